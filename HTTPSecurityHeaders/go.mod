@@ -1,3 +0,0 @@
-module github.com/MantisSTS/PrismTools/HTTPSecurityHeaders
-
-go 1.19
@@ -1,3 +0,0 @@
-module github.com/MantisSTS/PrismTools/HostRemove
-
-go 1.19
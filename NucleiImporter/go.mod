@@ -1,3 +0,0 @@
-module github.com/MantisSTS/PrismTools/NucleiImporter
-
-go 1.19
@@ -0,0 +1,25 @@
+// prism-schema writes the JSON Schema for Prism files generated from the
+// PrismDataStructs types. Run it through "go generate" in PrismDataStructs.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func main() {
+	outputFile := flag.String("o", "prism.schema.json", "File to write the schema to")
+	flag.Parse()
+
+	schema, err := PrismDataStructs.MarshalSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = os.WriteFile(*outputFile, schema, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
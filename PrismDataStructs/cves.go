@@ -0,0 +1,29 @@
+package PrismDataStructs
+
+// MaxCvesLength is the most characters Prism accepts in an issue's CVE list,
+// which it stores one CVE per line with \r\n line endings
+const MaxCvesLength = 10000
+
+// TruncateCves drops the CVEs from the one that takes the list over
+// MaxCvesLength characters onwards, and returns how many it dropped. Scanners
+// such as Trivy can list thousands of CVEs against one package.
+func (i *Issue) TruncateCves() int {
+	if i.Cves == nil {
+		return 0
+	}
+
+	length := 0
+	for index, cve := range *i.Cves {
+		if index > 0 {
+			length += len("\r\n")
+		}
+		length += len(cve)
+		if length > MaxCvesLength {
+			dropped := len(*i.Cves) - index
+			cves := append([]string(nil), (*i.Cves)[:index]...)
+			i.Cves = &cves
+			return dropped
+		}
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package PrismDataStructs
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTruncateCves(t *testing.T) {
+	// Each CVE-2023-NNNNN takes 16 characters with the line ending before
+	// it, so 625 take 9,998
+	var cves []string
+	for n := 0; n < 1000; n++ {
+		cves = append(cves, fmt.Sprintf("CVE-2023-%05d", n))
+	}
+	original := append([]string(nil), cves...)
+	issue := Issue{Cves: &cves}
+
+	if dropped := issue.TruncateCves(); dropped != 1000-625 {
+		t.Errorf("TruncateCves() = %d, want %d", dropped, 1000-625)
+	}
+	if len(*issue.Cves) != 625 {
+		t.Errorf("kept %d CVEs, want 625", len(*issue.Cves))
+	}
+	if cves[999] != original[999] {
+		t.Error("TruncateCves changed the caller's slice")
+	}
+
+	if dropped := issue.TruncateCves(); dropped != 0 {
+		t.Errorf("second TruncateCves() = %d, want 0", dropped)
+	}
+	if dropped := (&Issue{}).TruncateCves(); dropped != 0 {
+		t.Errorf("TruncateCves() without CVEs = %d, want 0", dropped)
+	}
+}
+
+func TestTruncateCvesAtLimit(t *testing.T) {
+	// 624 CVE-2023-NNNNN and a 16 character CVE-2023-NNNNNNN take exactly
+	// MaxCvesLength characters, which Prism accepts
+	var cves []string
+	for n := 0; n < 624; n++ {
+		cves = append(cves, fmt.Sprintf("CVE-2023-%05d", n))
+	}
+	cves = append(cves, "CVE-2023-1234567")
+	atLimit := Issue{Cves: &cves}
+	if dropped := atLimit.TruncateCves(); dropped != 0 {
+		t.Errorf("TruncateCves() at the limit = %d, want 0", dropped)
+	}
+
+	// One more character takes the list over it
+	over := append(append([]string(nil), cves[:624]...), "CVE-2023-12345678")
+	overLimit := Issue{Cves: &over}
+	if dropped := overLimit.TruncateCves(); dropped != 1 {
+		t.Errorf("TruncateCves() over the limit = %d, want 1", dropped)
+	}
+}
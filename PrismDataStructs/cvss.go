@@ -0,0 +1,386 @@
+package PrismDataStructs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	v2 "github.com/goark/go-cvss/v2/base"
+	"github.com/goark/go-cvss/v3/metric"
+)
+
+// CVSSVersion is the CVSS specification a vector is written against
+type CVSSVersion string
+
+const (
+	CVSSv2  CVSSVersion = "2.0"
+	CVSSv30 CVSSVersion = "3.0"
+	CVSSv31 CVSSVersion = "3.1"
+	CVSSv40 CVSSVersion = "4.0"
+)
+
+var (
+	// ErrNoCVSS is returned by Issue.CVSS when the issue has no vector
+	ErrNoCVSS = errors.New("no CVSS vector")
+
+	// ErrInvalidCVSS is wrapped by every error for a malformed vector
+	ErrInvalidCVSS = errors.New("invalid CVSS vector")
+)
+
+// CVSS is a parsed CVSS vector and its scores
+type CVSS struct {
+	Version CVSSVersion
+
+	// Vector is the normalised vector: metrics in specification order with
+	// their canonical case, no trailing slash, and CVSS v2 vectors without
+	// the CVSS2# prefix or parentheses
+	Vector string
+
+	// Scored is false for CVSS v4.0, which is parsed but not scored
+	Scored bool
+
+	// TemporalScore and EnvironmentalScore equal the base score when the
+	// vector has no temporal or environmental metrics. The environmental
+	// metrics of CVSS v2 vectors are parsed but not scored, so for those
+	// EnvironmentalScore is the temporal score.
+	BaseScore          float64
+	TemporalScore      float64
+	EnvironmentalScore float64
+
+	// Severity is the rating for the base score. CVSS v2 has no critical
+	// band, so v2 scores of 9.0 and above are High.
+	Severity RiskRating
+
+	metrics []cvssValue
+}
+
+type cvssValue struct {
+	name, value string
+}
+
+// cvssMetric is one metric of a CVSS version and the values it can take
+type cvssMetric struct {
+	name     string
+	values   []string
+	required bool
+}
+
+var (
+	cvssV2Metrics = []cvssMetric{
+		{"AV", []string{"L", "A", "N"}, true},
+		{"AC", []string{"H", "M", "L"}, true},
+		{"Au", []string{"M", "S", "N"}, true},
+		{"C", []string{"N", "P", "C"}, true},
+		{"I", []string{"N", "P", "C"}, true},
+		{"A", []string{"N", "P", "C"}, true},
+		{"E", []string{"U", "POC", "F", "H", "ND"}, false},
+		{"RL", []string{"OF", "TF", "W", "U", "ND"}, false},
+		{"RC", []string{"UC", "UR", "C", "ND"}, false},
+		{"CDP", []string{"N", "L", "LM", "MH", "H", "ND"}, false},
+		{"TD", []string{"N", "L", "M", "H", "ND"}, false},
+		{"CR", []string{"L", "M", "H", "ND"}, false},
+		{"IR", []string{"L", "M", "H", "ND"}, false},
+		{"AR", []string{"L", "M", "H", "ND"}, false},
+	}
+
+	cvssV3Metrics = []cvssMetric{
+		{"AV", []string{"N", "A", "L", "P"}, true},
+		{"AC", []string{"L", "H"}, true},
+		{"PR", []string{"N", "L", "H"}, true},
+		{"UI", []string{"N", "R"}, true},
+		{"S", []string{"U", "C"}, true},
+		{"C", []string{"H", "L", "N"}, true},
+		{"I", []string{"H", "L", "N"}, true},
+		{"A", []string{"H", "L", "N"}, true},
+		{"E", []string{"X", "H", "F", "P", "U"}, false},
+		{"RL", []string{"X", "U", "W", "T", "O"}, false},
+		{"RC", []string{"X", "C", "R", "U"}, false},
+		{"CR", []string{"X", "H", "M", "L"}, false},
+		{"IR", []string{"X", "H", "M", "L"}, false},
+		{"AR", []string{"X", "H", "M", "L"}, false},
+		{"MAV", []string{"X", "N", "A", "L", "P"}, false},
+		{"MAC", []string{"X", "L", "H"}, false},
+		{"MPR", []string{"X", "N", "L", "H"}, false},
+		{"MUI", []string{"X", "N", "R"}, false},
+		{"MS", []string{"X", "U", "C"}, false},
+		{"MC", []string{"X", "H", "L", "N"}, false},
+		{"MI", []string{"X", "H", "L", "N"}, false},
+		{"MA", []string{"X", "H", "L", "N"}, false},
+	}
+
+	cvssV4Metrics = []cvssMetric{
+		{"AV", []string{"N", "A", "L", "P"}, true},
+		{"AC", []string{"L", "H"}, true},
+		{"AT", []string{"N", "P"}, true},
+		{"PR", []string{"N", "L", "H"}, true},
+		{"UI", []string{"N", "P", "A"}, true},
+		{"VC", []string{"H", "L", "N"}, true},
+		{"VI", []string{"H", "L", "N"}, true},
+		{"VA", []string{"H", "L", "N"}, true},
+		{"SC", []string{"H", "L", "N"}, true},
+		{"SI", []string{"H", "L", "N"}, true},
+		{"SA", []string{"H", "L", "N"}, true},
+		{"E", []string{"X", "A", "P", "U"}, false},
+		{"CR", []string{"X", "H", "M", "L"}, false},
+		{"IR", []string{"X", "H", "M", "L"}, false},
+		{"AR", []string{"X", "H", "M", "L"}, false},
+		{"MAV", []string{"X", "N", "A", "L", "P"}, false},
+		{"MAC", []string{"X", "L", "H"}, false},
+		{"MAT", []string{"X", "N", "P"}, false},
+		{"MPR", []string{"X", "N", "L", "H"}, false},
+		{"MUI", []string{"X", "N", "P", "A"}, false},
+		{"MVC", []string{"X", "H", "L", "N"}, false},
+		{"MVI", []string{"X", "H", "L", "N"}, false},
+		{"MVA", []string{"X", "H", "L", "N"}, false},
+		{"MSC", []string{"X", "H", "L", "N"}, false},
+		{"MSI", []string{"X", "S", "H", "L", "N"}, false},
+		{"MSA", []string{"X", "S", "H", "L", "N"}, false},
+		{"S", []string{"X", "N", "P"}, false},
+		{"AU", []string{"X", "N", "Y"}, false},
+		{"R", []string{"X", "A", "U", "I"}, false},
+		{"V", []string{"X", "D", "C"}, false},
+		{"RE", []string{"X", "L", "M", "H"}, false},
+		{"U", []string{"X", "Clear", "Green", "Amber", "Red"}, false},
+	}
+
+	cvssMetrics = map[CVSSVersion][]cvssMetric{
+		CVSSv2:  cvssV2Metrics,
+		CVSSv30: cvssV3Metrics,
+		CVSSv31: cvssV3Metrics,
+		CVSSv40: cvssV4Metrics,
+	}
+)
+
+// CVSS parses the issue's CVSS vector, returning ErrNoCVSS when it has none
+func (i *Issue) CVSS() (CVSS, error) {
+	if i.CvssVector == nil || strings.TrimSpace(*i.CvssVector) == "" {
+		return CVSS{}, ErrNoCVSS
+	}
+	return ParseCVSS(*i.CvssVector)
+}
+
+// ParseCVSS parses and scores a CVSS v2, v3.0, v3.1 or v4.0 vector. CVSS v2
+// vectors may be bare, in parentheses or prefixed with CVSS2#, as Nessus
+// writes them. Metric names and values are matched case-insensitively and
+// a trailing slash is ignored, but unknown or duplicated metrics, invalid
+// values and missing base metrics are errors wrapping ErrInvalidCVSS.
+func ParseCVSS(vector string) (CVSS, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidCVSS, vector, fmt.Sprintf(format, args...))
+	}
+
+	version, body, err := splitCVSSVersion(strings.TrimRight(strings.TrimSpace(vector), "/"))
+	if err != nil {
+		return CVSS{}, invalid("%v", err)
+	}
+
+	definitions := cvssMetrics[version]
+	found := make(map[string]string)
+	for _, part := range strings.Split(body, "/") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return CVSS{}, invalid("%q is not a metric", part)
+		}
+
+		definition, ok := findCVSSMetric(definitions, name)
+		if !ok {
+			return CVSS{}, invalid("unknown metric %q", name)
+		}
+		if _, ok := found[definition.name]; ok {
+			return CVSS{}, invalid("metric %s is repeated", definition.name)
+		}
+
+		canonical, ok := findCVSSValue(definition.values, value)
+		if !ok {
+			return CVSS{}, invalid("invalid value %q for metric %s", value, definition.name)
+		}
+		found[definition.name] = canonical
+	}
+
+	c := CVSS{Version: version}
+	for _, definition := range definitions {
+		value, ok := found[definition.name]
+		if !ok {
+			if definition.required {
+				return CVSS{}, invalid("missing metric %s", definition.name)
+			}
+			continue
+		}
+		c.metrics = append(c.metrics, cvssValue{definition.name, value})
+	}
+
+	c.Vector = c.encode()
+	if err := c.score(); err != nil {
+		return CVSS{}, invalid("%v", err)
+	}
+	return c, nil
+}
+
+// Metric returns the value of a metric, such as "AV", if the vector has it
+func (c CVSS) Metric(name string) (string, bool) {
+	for _, m := range c.metrics {
+		if strings.EqualFold(m.name, name) {
+			return m.value, true
+		}
+	}
+	return "", false
+}
+
+// Upgrade re-scores a CVSS v3.0 vector as v3.1. Vectors that are already
+// v3.1 or v4.0 are returned as they are, and v2 vectors cannot be upgraded
+// because the two versions do not share metrics.
+func (c CVSS) Upgrade() (CVSS, error) {
+	switch c.Version {
+	case CVSSv30:
+		return ParseCVSS("CVSS:" + string(CVSSv31) + strings.TrimPrefix(c.Vector, "CVSS:"+string(CVSSv30)))
+	case CVSSv2:
+		return c, fmt.Errorf("CVSS v2 vector %q cannot be upgraded to v3.1", c.Vector)
+	default:
+		return c, nil
+	}
+}
+
+// String returns the normalised vector
+func (c CVSS) String() string {
+	return c.Vector
+}
+
+func splitCVSSVersion(vector string) (CVSSVersion, string, error) {
+	if vector == "" {
+		return "", "", fmt.Errorf("vector is empty")
+	}
+
+	if strings.HasPrefix(strings.ToUpper(vector), "CVSS:") {
+		prefix, body, _ := strings.Cut(vector, "/")
+		version := CVSSVersion(prefix[len("CVSS:"):])
+		switch version {
+		case CVSSv30, CVSSv31, CVSSv40:
+			return version, body, nil
+		}
+		return "", "", fmt.Errorf("unsupported version %q", version)
+	}
+
+	// CVSS v2 vectors have no version metric
+	if strings.HasPrefix(strings.ToUpper(vector), "CVSS2#") {
+		return CVSSv2, vector[len("CVSS2#"):], nil
+	}
+	if strings.HasPrefix(vector, "(") && strings.HasSuffix(vector, ")") {
+		return CVSSv2, vector[1 : len(vector)-1], nil
+	}
+	if strings.Contains(strings.ToUpper(vector), "AU:") {
+		return CVSSv2, vector, nil
+	}
+	return "", "", fmt.Errorf("missing CVSS version prefix")
+}
+
+func findCVSSMetric(definitions []cvssMetric, name string) (cvssMetric, bool) {
+	for _, definition := range definitions {
+		if strings.EqualFold(definition.name, name) {
+			return definition, true
+		}
+	}
+	return cvssMetric{}, false
+}
+
+func findCVSSValue(values []string, value string) (string, bool) {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (c CVSS) encode() string {
+	parts := make([]string, 0, len(c.metrics)+1)
+	if c.Version != CVSSv2 {
+		parts = append(parts, "CVSS:"+string(c.Version))
+	}
+	for _, m := range c.metrics {
+		parts = append(parts, m.name+":"+m.value)
+	}
+	return strings.Join(parts, "/")
+}
+
+func (c *CVSS) score() error {
+	switch c.Version {
+	case CVSSv2:
+		return c.scoreV2()
+	case CVSSv30, CVSSv31:
+		return c.scoreV3()
+	}
+	return nil
+}
+
+func (c *CVSS) scoreV2() error {
+
+	// go-cvss only knows the base and temporal metrics of v2
+	var scored []string
+	for _, m := range c.metrics {
+		switch m.name {
+		case "CDP", "TD", "CR", "IR", "AR":
+			continue
+		}
+		scored = append(scored, m.name+":"+m.value)
+	}
+
+	metrics, err := v2.Decode(strings.Join(scored, "/"))
+	if err != nil {
+		return err
+	}
+
+	c.Scored = true
+	c.BaseScore = metrics.Score()
+	c.TemporalScore = metrics.TemporalScore()
+	c.EnvironmentalScore = c.TemporalScore
+	c.Severity = RiskRatingFromCVSS(c.BaseScore)
+	if c.Severity == RiskCritical {
+		c.Severity = RiskHigh
+	}
+	return nil
+}
+
+func (c *CVSS) scoreV3() error {
+	em, err := metric.NewEnvironmental().Decode(c.Vector)
+	if err != nil {
+		return err
+	}
+
+	c.Scored = true
+	c.BaseScore = em.Base.Score()
+	c.TemporalScore = em.Temporal.Score()
+	c.EnvironmentalScore = em.Score()
+	if c.Version == CVSSv30 {
+		c.EnvironmentalScore = environmentalScoreV30(em)
+	}
+	c.Severity = RiskRatingFromCVSS(c.BaseScore)
+	return nil
+}
+
+// environmentalScoreV30 applies the CVSS v3.0 environmental formula. go-cvss
+// only implements v3.1, which changed the modified impact for a changed scope
+// and the rounding function.
+func environmentalScoreV30(em *metric.Environmental) float64 {
+	roundUp := func(x float64) float64 {
+		return math.Ceil(x*10) / 10
+	}
+
+	changed := em.MS == metric.ModifiedScopeChanged ||
+		(em.MS == metric.ModifiedScopeNotDefined && em.S == metric.ScopeChanged)
+
+	subScore := math.Min(1-(1-em.CR.Value()*em.MC.Value(em.C))*(1-em.IR.Value()*em.MI.Value(em.I))*(1-em.AR.Value()*em.MA.Value(em.A)), 0.915)
+	impact := 6.42 * subScore
+	if changed {
+		impact = 7.52*(subScore-0.029) - 3.25*math.Pow(subScore-0.02, 15)
+	}
+	exploitability := 8.22 * em.MAV.Value(em.AV) * em.MAC.Value(em.AC) * em.MPR.Value(em.MS, em.S, em.PR) * em.MUI.Value(em.UI)
+
+	if impact <= 0 {
+		return 0
+	}
+	if changed {
+		return roundUp(roundUp(math.Min(1.08*(impact+exploitability), 10)) * em.E.Value() * em.RL.Value() * em.RC.Value())
+	}
+	return roundUp(roundUp(math.Min(impact+exploitability, 10)) * em.E.Value() * em.RL.Value() * em.RC.Value())
+}
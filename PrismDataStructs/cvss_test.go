@@ -0,0 +1,97 @@
+package PrismDataStructs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCVSS(t *testing.T) {
+	tests := []struct {
+		vector   string
+		version  CVSSVersion
+		base     float64
+		temporal float64
+		severity RiskRating
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/", CVSSv31, 9.8, 9.8, RiskCritical},
+		{"cvss:3.0/av:n/ac:l/pr:n/ui:n/s:c/c:h/i:h/a:h", CVSSv30, 10, 10, RiskCritical},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N/E:U/RL:O/RC:C", CVSSv31, 3.3, 2.9, RiskLow},
+		{"CVSS2#AV:N/AC:L/Au:N/C:P/I:P/A:P", CVSSv2, 7.5, 7.5, RiskHigh},
+		{"(AV:N/AC:L/Au:N/C:C/I:C/A:C/E:F)", CVSSv2, 10, 9.5, RiskHigh},
+	}
+	for _, test := range tests {
+		c, err := ParseCVSS(test.vector)
+		if err != nil {
+			t.Errorf("ParseCVSS(%q): %v", test.vector, err)
+			continue
+		}
+		if c.Version != test.version || c.BaseScore != test.base || c.TemporalScore != test.temporal || c.Severity != test.severity {
+			t.Errorf("ParseCVSS(%q) = %s %v/%v %s, want %s %v/%v %s", test.vector,
+				c.Version, c.BaseScore, c.TemporalScore, c.Severity,
+				test.version, test.base, test.temporal, test.severity)
+		}
+	}
+
+	c, _ := ParseCVSS("cvss:3.0/av:n/ac:l/pr:n/ui:n/s:c/c:h/i:h/a:h")
+	if c.Vector != "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H" {
+		t.Errorf("Vector = %q", c.Vector)
+	}
+}
+
+func TestParseCVSSv4(t *testing.T) {
+	c, err := ParseCVSS("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/U:red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Scored {
+		t.Error("CVSS v4.0 vectors should not be scored")
+	}
+	if value, _ := c.Metric("U"); value != "Red" {
+		t.Errorf("U = %q, want Red", value)
+	}
+}
+
+func TestParseCVSSErrors(t *testing.T) {
+	for _, vector := range []string{
+		"",
+		"AV:N/AC:L",
+		"CVSS:2.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:X",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",
+		"CVSS:3.1/AV:N/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/ZZ:1",
+		"CVSS:4.0/AV:N/AC:L/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+	} {
+		if _, err := ParseCVSS(vector); !errors.Is(err, ErrInvalidCVSS) {
+			t.Errorf("ParseCVSS(%q) = %v, want ErrInvalidCVSS", vector, err)
+		}
+	}
+
+	if _, err := (&Issue{}).CVSS(); err != ErrNoCVSS {
+		t.Errorf("Issue.CVSS() = %v, want ErrNoCVSS", err)
+	}
+}
+
+func TestCVSSUpgrade(t *testing.T) {
+	// The v3.0 and v3.1 environmental formulas differ for a changed scope
+	c, err := ParseCVSS("CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:C/C:H/I:H/A:H/CR:H/IR:H/AR:H")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upgraded, err := c.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgraded.Version != CVSSv31 || upgraded.Vector != "CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:C/C:H/I:H/A:H/CR:H/IR:H/AR:H" {
+		t.Errorf("Upgrade() = %s %q", upgraded.Version, upgraded.Vector)
+	}
+	if c.EnvironmentalScore != 8.3 || upgraded.EnvironmentalScore != 8.4 {
+		t.Errorf("environmental scores = %v -> %v, want 8.3 -> 8.4", c.EnvironmentalScore, upgraded.EnvironmentalScore)
+	}
+
+	v2, _ := ParseCVSS("AV:N/AC:L/Au:N/C:P/I:P/A:P")
+	if _, err := v2.Upgrade(); err == nil {
+		t.Error("CVSS v2 vectors should not upgrade")
+	}
+}
@@ -0,0 +1,137 @@
+package PrismDataStructs
+
+// IssueChange is an issue that is new, resolved or persisting between two
+// versions of a file
+type IssueChange struct {
+	Issue  string     `json:"issue"`
+	Rating RiskRating `json:"rating"`
+	Hosts  []string   `json:"hosts"`
+
+	// Before and After are the issue in each file, nil where it is missing
+	Before *Issue `json:"-"`
+	After  *Issue `json:"-"`
+}
+
+// HostChange is an affected host added to or removed from a persisting issue
+type HostChange struct {
+	Issue string `json:"issue"`
+	Host  string `json:"host"`
+}
+
+// RatingChange is a persisting issue whose original risk rating changed
+type RatingChange struct {
+	Issue string     `json:"issue"`
+	From  RiskRating `json:"from"`
+	To    RiskRating `json:"to"`
+}
+
+// ChangeSet is the difference between an original file and a retest of it
+type ChangeSet struct {
+	New           []IssueChange  `json:"new"`
+	Resolved      []IssueChange  `json:"resolved"`
+	Persisting    []IssueChange  `json:"persisting"`
+	HostAdded     []HostChange   `json:"host_added"`
+	HostRemoved   []HostChange   `json:"host_removed"`
+	RatingChanged []RatingChange `json:"rating_changed"`
+
+	after *Prism
+}
+
+// Diff compares an original file with a retest, matching issues by
+// identities (DefaultIssueIdentities when none are given) and hosts by
+// HostKey. Issues only in after are new, issues only in before are resolved
+// and the rest are persisting, with their host and rating changes listed
+// separately. Each original issue persists at most once: a second retest
+// issue matching the same original is new.
+func Diff(before, after *Prism, identities ...IssueIdentity) *ChangeSet {
+	changes := &ChangeSet{
+		New:           []IssueChange{},
+		Resolved:      []IssueChange{},
+		Persisting:    []IssueChange{},
+		HostAdded:     []HostChange{},
+		HostRemoved:   []HostChange{},
+		RatingChanged: []RatingChange{},
+		after:         after,
+	}
+
+	index := newIssueIndex(identities)
+	for i := range before.Issues {
+		index.add(&before.Issues[i], i)
+	}
+
+	matched := make([]bool, len(before.Issues))
+	for i := range after.Issues {
+		retest := &after.Issues[i]
+		position, ok := index.find(retest)
+		if !ok || matched[position] {
+			changes.New = append(changes.New, newIssueChange(nil, retest))
+			continue
+		}
+
+		original := &before.Issues[position]
+		matched[position] = true
+		changes.Persisting = append(changes.Persisting, newIssueChange(original, retest))
+
+		originalHosts := NewHostSet(original.AffectedHosts...)
+		retestHosts := NewHostSet(retest.AffectedHosts...)
+		for _, key := range retestHosts.Keys() {
+			if !originalHosts.Contains(key) {
+				changes.HostAdded = append(changes.HostAdded, HostChange{Issue: retest.Name, Host: key.String()})
+			}
+		}
+		for _, key := range originalHosts.Keys() {
+			if !retestHosts.Contains(key) {
+				changes.HostRemoved = append(changes.HostRemoved, HostChange{Issue: retest.Name, Host: key.String()})
+			}
+		}
+
+		if original.OriginalRiskRating != retest.OriginalRiskRating {
+			changes.RatingChanged = append(changes.RatingChanged, RatingChange{
+				Issue: retest.Name,
+				From:  original.OriginalRiskRating,
+				To:    retest.OriginalRiskRating,
+			})
+		}
+	}
+
+	for i := range before.Issues {
+		if !matched[i] {
+			changes.Resolved = append(changes.Resolved, newIssueChange(&before.Issues[i], nil))
+		}
+	}
+	return changes
+}
+
+func newIssueChange(before, after *Issue) IssueChange {
+	issue := after
+	if issue == nil {
+		issue = before
+	}
+
+	change := IssueChange{Issue: issue.Name, Rating: issue.OriginalRiskRating, Hosts: []string{}, Before: before, After: after}
+	for _, key := range NewHostSet(issue.AffectedHosts...).Keys() {
+		change.Hosts = append(change.Hosts, key.String())
+	}
+	return change
+}
+
+// Prism returns the retest file with the resolved issues added back after
+// its own issues. When status or remediatedAt are not empty they are set on
+// the resolved issues, which are copied so the original file is unchanged.
+func (c *ChangeSet) Prism(status, remediatedAt string) *Prism {
+	prism := *c.after
+	prism.Issues = append([]Issue(nil), c.after.Issues...)
+
+	for _, change := range c.Resolved {
+		issue := *change.Before
+		if status != "" {
+			issue.Status = status
+		}
+		if remediatedAt != "" {
+			date := remediatedAt
+			issue.RemediatedAt = &date
+		}
+		prism.Issues = append(prism.Issues, issue)
+	}
+	return &prism
+}
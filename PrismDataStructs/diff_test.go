@@ -0,0 +1,67 @@
+package PrismDataStructs
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	before := &Prism{Issues: []Issue{
+		{Name: "Expired certificate", OriginalRiskRating: RiskMedium, AffectedHosts: []AffectedHost{{Ip: "10.0.0.1", Port: NewPort(443)}, {Ip: "10.0.0.2", Port: NewPort(443)}}},
+		{Name: "SMB signing not required", OriginalRiskRating: RiskMedium, AffectedHosts: []AffectedHost{{Ip: "10.0.0.3"}}},
+	}}
+	after := &Prism{Issues: []Issue{
+		{Name: "expired certificate", OriginalRiskRating: RiskHigh, AffectedHosts: []AffectedHost{{Ip: "10.0.0.1", Port: NewPort(443)}, {Ip: "10.0.0.1", Port: NewPort(8443)}}},
+		{Name: "Telnet enabled", OriginalRiskRating: RiskHigh, AffectedHosts: []AffectedHost{{Ip: "10.0.0.4", Port: NewPort(23)}}},
+	}}
+
+	changes := Diff(before, after)
+	if len(changes.New) != 1 || changes.New[0].Issue != "Telnet enabled" {
+		t.Errorf("new = %+v", changes.New)
+	}
+	if len(changes.Resolved) != 1 || changes.Resolved[0].Issue != "SMB signing not required" {
+		t.Errorf("resolved = %+v", changes.Resolved)
+	}
+	if len(changes.Persisting) != 1 {
+		t.Errorf("persisting = %+v", changes.Persisting)
+	}
+	if len(changes.HostAdded) != 1 || changes.HostAdded[0].Host != "10.0.0.1:8443/tcp" {
+		t.Errorf("host added = %+v", changes.HostAdded)
+	}
+	if len(changes.HostRemoved) != 1 || changes.HostRemoved[0].Host != "10.0.0.2:443/tcp" {
+		t.Errorf("host removed = %+v", changes.HostRemoved)
+	}
+	if len(changes.RatingChanged) != 1 || changes.RatingChanged[0].From != RiskMedium || changes.RatingChanged[0].To != RiskHigh {
+		t.Errorf("rating changed = %+v", changes.RatingChanged)
+	}
+
+	prism := changes.Prism("remediated", "2024-01-31")
+	if len(prism.Issues) != 3 {
+		t.Fatalf("%d issues, want the retest plus the resolved issue", len(prism.Issues))
+	}
+	resolved := prism.Issues[2]
+	if resolved.Status != "remediated" || resolved.RemediatedAt == nil || *resolved.RemediatedAt != "2024-01-31" {
+		t.Errorf("resolved issue not marked: %q %v", resolved.Status, resolved.RemediatedAt)
+	}
+	if before.Issues[1].Status != "" {
+		t.Error("the original file should not change")
+	}
+}
+
+func TestDiffMatchesEachOriginalOnce(t *testing.T) {
+	before := &Prism{Issues: []Issue{
+		{Name: "Outdated OpenSSL", Cves: &[]string{"CVE-2022-0778"}, OriginalRiskRating: RiskHigh, AffectedHosts: []AffectedHost{{Ip: "10.0.0.1"}}},
+	}}
+	after := &Prism{Issues: []Issue{
+		{Name: "Outdated OpenSSL", OriginalRiskRating: RiskHigh, AffectedHosts: []AffectedHost{{Ip: "10.0.0.1"}}},
+		{Name: "outdated openssl", OriginalRiskRating: RiskHigh, AffectedHosts: []AffectedHost{{Ip: "10.0.0.2"}}},
+	}}
+
+	changes := Diff(before, after)
+	if len(changes.Persisting) != 1 || changes.Persisting[0].After != &after.Issues[0] {
+		t.Errorf("persisting = %+v, want only the first retest issue", changes.Persisting)
+	}
+	if len(changes.New) != 1 || changes.New[0].After != &after.Issues[1] {
+		t.Errorf("new = %+v, want the second retest issue", changes.New)
+	}
+	if len(changes.Resolved) != 0 || len(changes.HostAdded) != 0 || len(changes.HostRemoved) != 0 {
+		t.Errorf("resolved = %+v, host added = %+v, host removed = %+v", changes.Resolved, changes.HostAdded, changes.HostRemoved)
+	}
+}
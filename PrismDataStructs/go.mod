@@ -1,3 +1,7 @@
 module github.com/MantisSTS/PrismTools/PrismDataStructs
 
 go 1.19
+
+require github.com/goark/go-cvss v1.3.0
+
+require github.com/goark/errs v1.1.0 // indirect
@@ -0,0 +1,280 @@
+package PrismDataStructs
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// HostKey identifies an affected host. Two hosts are the same host when
+// their keys are equal, and HostKey is comparable so it can be used directly
+// as a map key.
+type HostKey struct {
+	// IP is in canonical form (IPv6 compressed and lower case, IPv4-mapped
+	// IPv6 unmapped), empty when the host has no IP address
+	IP string
+
+	// Hostname is lower case without a trailing dot
+	Hostname string
+
+	// Port is 0 when the host has no port
+	Port int
+
+	// Protocol is lower case, and defaults to tcp when a port is set
+	Protocol string
+
+	// Name identifies a host with neither an IP nor a hostname, such as a
+	// source file imported from SARIF, as its location and name
+	Name string
+}
+
+// NewHostKey builds a normalised key. An ip that is not an IP address, as
+// written by tools that put hostnames in the ip field, is used as the
+// hostname when hostname is empty.
+func NewHostKey(ip, hostname string, port int, protocol string) HostKey {
+	key := HostKey{
+		IP:       normaliseIP(ip),
+		Hostname: normaliseHostname(hostname),
+		Port:     port,
+		Protocol: strings.ToLower(strings.TrimSpace(protocol)),
+	}
+
+	if key.IP == "" && key.Hostname == "" {
+		key.Hostname = normaliseHostname(ip)
+	}
+	if key.Port != 0 && key.Protocol == "" {
+		key.Protocol = "tcp"
+	}
+	return key
+}
+
+// Key returns the identity of the affected host
+func (h AffectedHost) Key() HostKey {
+	protocol := ""
+	if h.Protocol != nil {
+		protocol = *h.Protocol
+	}
+	key := NewHostKey(h.Ip, h.Hostname, h.Port.Int(), protocol)
+
+	if key.IP == "" && key.Hostname == "" && h.Name != nil {
+		key.Name = strings.TrimSpace(*h.Name)
+		if h.Location != nil && strings.TrimSpace(*h.Location) != "" {
+			key.Name = strings.TrimRight(strings.TrimSpace(*h.Location), "/") + "/" + key.Name
+		}
+	}
+	return key
+}
+
+// hostProtocols are the protocols ParseHostKey accepts after a slash
+var hostProtocols = map[string]bool{"tcp": true, "udp": true, "sctp": true, "icmp": true}
+
+// ParseHostKey reads a host written as an address or hostname with an
+// optional port and protocol, such as 10.0.0.1, 10.0.0.1:443, [::1]:8443/tcp
+// or www.example.com:53/udp
+func ParseHostKey(s string) (HostKey, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return HostKey{}, fmt.Errorf("empty host")
+	}
+
+	host, protocol := s, ""
+	if slash := strings.LastIndex(s, "/"); slash >= 0 {
+		host, protocol = s[:slash], strings.ToLower(s[slash+1:])
+		if !hostProtocols[protocol] {
+			if _, err := strconv.Atoi(protocol); err == nil {
+				return HostKey{}, fmt.Errorf("address ranges such as %q are not supported, list each host", s)
+			}
+			return HostKey{}, fmt.Errorf("unknown protocol %q in host %q", protocol, s)
+		}
+	}
+
+	port := 0
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		port, err = strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return HostKey{}, fmt.Errorf("invalid port in host %q", s)
+		}
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+
+	if _, err := netip.ParseAddr(host); err == nil {
+		return NewHostKey(host, "", port, protocol), nil
+	}
+	return NewHostKey("", host, port, protocol), nil
+}
+
+// Match reports whether h matches pattern, where the zero value of each
+// pattern field matches anything. A pattern with only an address or only a
+// hostname matches that name on the IP or hostname of h, so "10.0.0.1"
+// matches every port of that host.
+func (h HostKey) Match(pattern HostKey) bool {
+	if pattern.IP != "" && pattern.IP != h.IP {
+		return false
+	}
+	if pattern.Hostname != "" && pattern.Hostname != h.Hostname {
+		return false
+	}
+	if pattern.Port != 0 && pattern.Port != h.Port {
+		return false
+	}
+	if pattern.Protocol != "" && pattern.Port != 0 && pattern.Protocol != h.Protocol {
+		return false
+	}
+	return pattern.IP != "" || pattern.Hostname != "" || pattern.Port != 0
+}
+
+// Address returns the IP, or the hostname when there is no IP, or the name
+// when there is neither
+func (h HostKey) Address() string {
+	if h.IP != "" {
+		return h.IP
+	}
+	if h.Hostname != "" {
+		return h.Hostname
+	}
+	return h.Name
+}
+
+// String formats the key as address[:port][/protocol], with the hostname
+// in front when the host has both
+func (h HostKey) String() string {
+	address := h.Address()
+	if h.Port != 0 {
+		address = net.JoinHostPort(address, strconv.Itoa(h.Port))
+	}
+	if h.Protocol != "" {
+		address += "/" + h.Protocol
+	}
+	if h.IP != "" && h.Hostname != "" {
+		address = h.Hostname + " " + address
+	}
+	return address
+}
+
+// Hash returns a stable 64-bit FNV-1a hash of the key
+func (h HostKey) Hash() uint64 {
+	hash := fnv.New64a()
+	for _, part := range []string{h.IP, h.Hostname, strconv.Itoa(h.Port), h.Protocol} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	if h.Name != "" {
+		hash.Write([]byte(h.Name))
+	}
+	return hash.Sum64()
+}
+
+func normaliseIP(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), "[]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return ""
+	}
+	return addr.Unmap().String()
+}
+
+func normaliseHostname(s string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+}
+
+// HostSet is an ordered set of affected hosts keyed by HostKey
+type HostSet struct {
+	hosts []AffectedHost
+	index map[HostKey]int
+}
+
+// NewHostSet returns a set holding hosts, keeping the first of any duplicates
+func NewHostSet(hosts ...AffectedHost) *HostSet {
+	set := &HostSet{index: make(map[HostKey]int)}
+	for _, host := range hosts {
+		set.Add(host)
+	}
+	return set
+}
+
+// Add inserts host and reports whether it was new
+func (s *HostSet) Add(host AffectedHost) bool {
+	key := host.Key()
+	if _, ok := s.index[key]; ok {
+		return false
+	}
+	s.index[key] = len(s.hosts)
+	s.hosts = append(s.hosts, host)
+	return true
+}
+
+// Contains reports whether a host with the given key is in the set
+func (s *HostSet) Contains(key HostKey) bool {
+	_, ok := s.index[key]
+	return ok
+}
+
+// Get returns the host with the given key
+func (s *HostSet) Get(key HostKey) (AffectedHost, bool) {
+	i, ok := s.index[key]
+	if !ok {
+		return AffectedHost{}, false
+	}
+	return s.hosts[i], true
+}
+
+// Set stores host, replacing any host with the same key in place
+func (s *HostSet) Set(host AffectedHost) {
+	if i, ok := s.index[host.Key()]; ok {
+		s.hosts[i] = host
+		return
+	}
+	s.Add(host)
+}
+
+// Remove deletes the host with the given key and reports whether it was present
+func (s *HostSet) Remove(key HostKey) bool {
+	i, ok := s.index[key]
+	if !ok {
+		return false
+	}
+
+	s.hosts = append(s.hosts[:i], s.hosts[i+1:]...)
+	delete(s.index, key)
+	for j := i; j < len(s.hosts); j++ {
+		s.index[s.hosts[j].Key()] = j
+	}
+	return true
+}
+
+// RemoveMatching deletes every host matching pattern (see HostKey.Match)
+// and returns them
+func (s *HostSet) RemoveMatching(pattern HostKey) []AffectedHost {
+	var removed []AffectedHost
+	for _, key := range s.Keys() {
+		if key.Match(pattern) {
+			host, _ := s.Get(key)
+			removed = append(removed, host)
+			s.Remove(key)
+		}
+	}
+	return removed
+}
+
+// Len returns the number of hosts in the set
+func (s *HostSet) Len() int {
+	return len(s.hosts)
+}
+
+// Hosts returns the hosts in insertion order
+func (s *HostSet) Hosts() []AffectedHost {
+	return append([]AffectedHost(nil), s.hosts...)
+}
+
+// Keys returns the keys in insertion order
+func (s *HostSet) Keys() []HostKey {
+	keys := make([]HostKey, len(s.hosts))
+	for i, host := range s.hosts {
+		keys[i] = host.Key()
+	}
+	return keys
+}
@@ -0,0 +1,80 @@
+package PrismDataStructs
+
+import "testing"
+
+func TestHostKeyNormalisation(t *testing.T) {
+	tcp := "TCP"
+	file, repository := "src/app.go", "https://github.com/example/app/"
+	tests := []struct {
+		host AffectedHost
+		want HostKey
+	}{
+		{AffectedHost{Ip: "10.0.0.1", Port: NewPort(443), Protocol: &tcp}, HostKey{IP: "10.0.0.1", Port: 443, Protocol: "tcp"}},
+		{AffectedHost{Ip: "::ffff:10.0.0.1"}, HostKey{IP: "10.0.0.1"}},
+		{AffectedHost{Ip: "2001:DB8:0:0::1", Port: NewPort(22)}, HostKey{IP: "2001:db8::1", Port: 22, Protocol: "tcp"}},
+		{AffectedHost{Ip: "WWW.Example.com.", Hostname: ""}, HostKey{Hostname: "www.example.com"}},
+		{AffectedHost{Ip: "10.0.0.1", Hostname: "Web01."}, HostKey{IP: "10.0.0.1", Hostname: "web01"}},
+		{AffectedHost{Name: &file}, HostKey{Name: "src/app.go"}},
+		{AffectedHost{Name: &file, Location: &repository}, HostKey{Name: "https://github.com/example/app/src/app.go"}},
+		{AffectedHost{Ip: "10.0.0.1", Name: &file}, HostKey{IP: "10.0.0.1"}},
+	}
+	for _, test := range tests {
+		if got := test.host.Key(); got != test.want {
+			t.Errorf("Key() = %+v, want %+v", got, test.want)
+		}
+	}
+
+	a := AffectedHost{Ip: "10.0.0.1", Port: NewPort(443)}.Key()
+	b := AffectedHost{Ip: "10.0.0.1", Port: NewPort(8443)}.Key()
+	if a == b || a.Hash() == b.Hash() {
+		t.Error("hosts on different ports should have different keys")
+	}
+
+	other := "src/db.go"
+	if (AffectedHost{Name: &file}).Key() == (AffectedHost{Name: &other}).Key() {
+		t.Error("hosts with different names should have different keys")
+	}
+}
+
+func TestParseHostKey(t *testing.T) {
+	tests := map[string]HostKey{
+		"10.0.0.1":            {IP: "10.0.0.1"},
+		"10.0.0.1:443":        {IP: "10.0.0.1", Port: 443, Protocol: "tcp"},
+		"[::1]:443/udp":       {IP: "::1", Port: 443, Protocol: "udp"},
+		"::1":                 {IP: "::1"},
+		"Host.Example.com:80": {Hostname: "host.example.com", Port: 80, Protocol: "tcp"},
+		"10.0.0.1:161/UDP":    {IP: "10.0.0.1", Port: 161, Protocol: "udp"},
+	}
+	for input, want := range tests {
+		got, err := ParseHostKey(input)
+		if err != nil || got != want {
+			t.Errorf("ParseHostKey(%q) = %+v, %v, want %+v", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "10.0.0.1:99999", "10.0.0.0/24", "10.0.0.1:443/tpc", "10.0.0.1/"} {
+		if _, err := ParseHostKey(input); err == nil {
+			t.Errorf("ParseHostKey(%q) should fail", input)
+		}
+	}
+}
+
+func TestHostSet(t *testing.T) {
+	hosts := NewHostSet(
+		AffectedHost{Ip: "10.0.0.1", Port: NewPort(443)},
+		AffectedHost{Ip: "10.0.0.1", Port: NewPort(8443)},
+		AffectedHost{Ip: "10.0.0.1", Port: NewPort(443)},
+		AffectedHost{Ip: "10.0.0.2"},
+	)
+	if hosts.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", hosts.Len())
+	}
+
+	pattern, _ := ParseHostKey("10.0.0.1")
+	if removed := hosts.RemoveMatching(pattern); len(removed) != 2 {
+		t.Errorf("removed %d hosts, want 2", len(removed))
+	}
+	if hosts.Len() != 1 || !hosts.Contains(HostKey{IP: "10.0.0.2"}) {
+		t.Errorf("unexpected hosts left: %v", hosts.Keys())
+	}
+}
@@ -0,0 +1,167 @@
+package PrismDataStructs
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IssueIdentity is one way of deciding that two issues, usually from
+// different files or tools, are the same finding
+type IssueIdentity string
+
+const (
+	// IdentityName matches issues by name, ignoring case and surrounding space
+	IdentityName IssueIdentity = "name"
+
+	// IdentityNessusId matches issues with the same Nessus plugin ID
+	IdentityNessusId IssueIdentity = "nessus-id"
+
+	// IdentityNucleiTemplate matches issues referencing the same nuclei template
+	IdentityNucleiTemplate IssueIdentity = "nuclei-template"
+
+	// IdentityCves matches issues with exactly the same set of CVEs
+	IdentityCves IssueIdentity = "cves"
+)
+
+// DefaultIssueIdentities are used when no identity is configured. The CVE
+// set is left out as unrelated checks often share one.
+var DefaultIssueIdentities = []IssueIdentity{IdentityNessusId, IdentityNucleiTemplate, IdentityName}
+
+// Hosts that serve nuclei templates, as written to template-url and references
+var nucleiTemplateHosts = []string{"nuclei-templates", "templates.nuclei.sh", "cloud.projectdiscovery.io"}
+
+// ParseIssueIdentities reads a comma separated list of identities such as
+// "name,nessus-id"
+func ParseIssueIdentities(s string) ([]IssueIdentity, error) {
+	var identities []IssueIdentity
+	for _, part := range strings.Split(s, ",") {
+		identity := IssueIdentity(strings.ToLower(strings.TrimSpace(part)))
+		switch identity {
+		case IdentityName, IdentityNessusId, IdentityNucleiTemplate, IdentityCves:
+			identities = append(identities, identity)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown issue identity %q", part)
+		}
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no issue identity given")
+	}
+	return identities, nil
+}
+
+// Key returns the issue's value for the identity, and false when the issue
+// has none (an unset Nessus ID, no nuclei template reference, no CVEs)
+func (identity IssueIdentity) Key(issue *Issue) (string, bool) {
+	switch identity {
+	case IdentityName:
+		name := strings.ToLower(strings.TrimSpace(issue.Name))
+		return name, name != ""
+	case IdentityNessusId:
+		nessusId, ok := issue.NessusId.Int64()
+		if !ok || nessusId == 0 {
+			return "", false
+		}
+		return strconv.FormatInt(nessusId, 10), true
+	case IdentityNucleiTemplate:
+		return issue.NucleiTemplateId()
+	case IdentityCves:
+		if issue.Cves == nil || len(*issue.Cves) == 0 {
+			return "", false
+		}
+		cves := uniqueStrings(nil, *issue.Cves, normaliseCve)
+		sort.Strings(cves)
+		return strings.Join(cves, ","), true
+	}
+	return "", false
+}
+
+// NucleiTemplateId returns the ID of the nuclei template in the issue's
+// references, such as cve-2021-44228 for
+// https://github.com/projectdiscovery/nuclei-templates/blob/main/http/cves/2021/CVE-2021-44228.yaml
+func (i *Issue) NucleiTemplateId() (string, bool) {
+	for _, reference := range i.References {
+		u, err := url.Parse(strings.TrimSpace(reference))
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		for _, host := range nucleiTemplateHosts {
+			if !strings.Contains(u.Host+u.Path, host) {
+				continue
+			}
+
+			id := path.Base(strings.TrimRight(u.Path, "/"))
+			id = strings.TrimSuffix(strings.TrimSuffix(id, ".yaml"), ".yml")
+			if id != "" && id != "." && id != "/" {
+				return strings.ToLower(id), true
+			}
+		}
+	}
+	return "", false
+}
+
+// issueIndex finds issues by any of a list of identities
+type issueIndex struct {
+	identities []IssueIdentity
+	keys       map[string]int
+}
+
+func newIssueIndex(identities []IssueIdentity) *issueIndex {
+	if len(identities) == 0 {
+		identities = DefaultIssueIdentities
+	}
+	return &issueIndex{identities: identities, keys: make(map[string]int)}
+}
+
+// find returns the position of the first indexed issue sharing an identity
+// with issue, trying the identities in order
+func (x *issueIndex) find(issue *Issue) (int, bool) {
+	for _, identity := range x.identities {
+		if key, ok := identity.Key(issue); ok {
+			if position, ok := x.keys[string(identity)+"\x00"+key]; ok {
+				return position, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// add records every identity of issue at position, keeping earlier entries
+func (x *issueIndex) add(issue *Issue, position int) {
+	for _, identity := range x.identities {
+		if key, ok := identity.Key(issue); ok {
+			if _, ok := x.keys[string(identity)+"\x00"+key]; !ok {
+				x.keys[string(identity)+"\x00"+key] = position
+			}
+		}
+	}
+}
+
+func normaliseCve(cve string) string {
+	return strings.ToUpper(strings.TrimSpace(cve))
+}
+
+// uniqueStrings appends the values not already in list, normalised by
+// normalise when it is not nil
+func uniqueStrings(list, values []string, normalise func(string) string) []string {
+	if normalise == nil {
+		normalise = func(s string) string { return s }
+	}
+
+	seen := make(map[string]bool, len(list)+len(values))
+	for _, value := range list {
+		seen[normalise(value)] = true
+	}
+	for _, value := range values {
+		if !seen[normalise(value)] {
+			seen[normalise(value)] = true
+			list = append(list, normalise(value))
+		}
+	}
+	return list
+}
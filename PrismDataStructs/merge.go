@@ -0,0 +1,321 @@
+package PrismDataStructs
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// MergeConflict is a field that differed between issues merged into one
+type MergeConflict struct {
+	Issue     string `json:"issue"`
+	Field     string `json:"field"`
+	Kept      string `json:"kept"`
+	Discarded string `json:"discarded"`
+
+	// Source is the input the later of the two issues came from
+	Source string `json:"source"`
+}
+
+func (c MergeConflict) String() string {
+	if c.Issue == "" {
+		return fmt.Sprintf("%s differs in %s: kept %q, discarded %q", c.Field, c.Source, c.Kept, c.Discarded)
+	}
+	return fmt.Sprintf("issue %q: %s differs in %s: kept %q, discarded %q", c.Issue, c.Field, c.Source, c.Kept, c.Discarded)
+}
+
+// Merger combines issues from several Prism files into one set, merging
+// issues that share an identity. Ratings that differ keep the most severe,
+// affected hosts, references and CVEs are unioned, and technical details
+// that differ are concatenated under the hosts they came from. Every other
+// field keeps the first value seen and takes later values only where it was
+// empty, and values that could not both be kept are reported as conflicts.
+type Merger struct {
+	index     *issueIndex
+	issues    []*mergedIssue
+	header    *Prism
+	conflicts []MergeConflict
+}
+
+type mergedIssue struct {
+	issue   Issue
+	hosts   *HostSet
+	details []detailsBlock
+}
+
+// detailsBlock is one distinct technical details text and the hosts it was
+// written for
+type detailsBlock struct {
+	details string
+	hosts   []HostKey
+}
+
+// NewMerger returns a Merger matching issues by identities, or by
+// DefaultIssueIdentities when none are given
+func NewMerger(identities ...IssueIdentity) *Merger {
+	return &Merger{index: newIssueIndex(identities)}
+}
+
+// AddHeader merges the header (phase, version and unknown keys) of a file.
+// The first named phase seen is kept.
+func (m *Merger) AddHeader(source string, header *Prism) {
+	if header == nil {
+		return
+	}
+	if m.header == nil {
+		m.header = &Prism{Phase: header.Phase, Extra: copyExtra(nil, header.Extra)}
+		return
+	}
+
+	if m.header.Phase.Name == "" {
+		m.header.Phase = header.Phase
+	} else if header.Phase.Name != "" && header.Phase.Name != m.header.Phase.Name {
+		m.conflict("", "phase.name", m.header.Phase.Name, header.Phase.Name, source)
+	}
+	m.header.Extra = copyExtra(m.header.Extra, header.Extra)
+}
+
+// AddIssue merges issue into the issue sharing one of its identities, or
+// adds it as a new issue
+func (m *Merger) AddIssue(source string, issue *Issue) {
+	position, ok := m.index.find(issue)
+	if !ok {
+		merged := &mergedIssue{issue: *issue, hosts: NewHostSet()}
+		merged.issue.References = append([]string(nil), issue.References...)
+		if issue.Cves != nil {
+			cves := append([]string(nil), *issue.Cves...)
+			merged.issue.Cves = &cves
+		}
+		merged.issue.Extra = copyExtra(nil, issue.Extra)
+		merged.addHosts(issue)
+
+		m.issues = append(m.issues, merged)
+		m.index.add(issue, len(m.issues)-1)
+		return
+	}
+
+	m.merge(m.issues[position], issue, source)
+	m.index.add(issue, position)
+}
+
+// Prism returns the merged file at the current version
+func (m *Merger) Prism() *Prism {
+	prism := &Prism{Version: CurrentVersion}
+	if m.header != nil {
+		prism.Phase = m.header.Phase
+		prism.Extra = m.header.Extra
+	}
+
+	prism.Issues = make([]Issue, 0, len(m.issues))
+	for _, merged := range m.issues {
+		issue := merged.issue
+		issue.AffectedHosts = merged.hosts.Hosts()
+		issue.TechnicalDetails = merged.technicalDetails()
+		prism.Issues = append(prism.Issues, issue)
+	}
+	return prism
+}
+
+// Conflicts returns the conflicts found so far, in the order they were found
+func (m *Merger) Conflicts() []MergeConflict {
+	return m.conflicts
+}
+
+func (m *Merger) conflict(issue, field, kept, discarded, source string) {
+	m.conflicts = append(m.conflicts, MergeConflict{Issue: issue, Field: field, Kept: kept, Discarded: discarded, Source: source})
+}
+
+func (m *Merger) merge(merged *mergedIssue, src *Issue, source string) {
+	dst := &merged.issue
+	merged.addHosts(src)
+
+	dst.References = uniqueStrings(dst.References, src.References, nil)
+	if src.Cves != nil {
+		var cves []string
+		if dst.Cves != nil {
+			cves = *dst.Cves
+		}
+		cves = uniqueStrings(cves, *src.Cves, normaliseCve)
+		dst.Cves = &cves
+	}
+
+	// Ratings keep the most severe
+	if src.OriginalRiskRating != dst.OriginalRiskRating {
+		kept, discarded := dst.OriginalRiskRating, src.OriginalRiskRating
+		if discarded.Compare(kept) > 0 {
+			kept, discarded = discarded, kept
+		}
+		dst.OriginalRiskRating = kept
+		m.conflict(dst.Name, "original_risk_rating", kept.String(), discarded.String(), source)
+	}
+	if src.ClientDefinedRiskRating != nil && *src.ClientDefinedRiskRating != "" {
+		if dst.ClientDefinedRiskRating == nil || *dst.ClientDefinedRiskRating == "" {
+			dst.ClientDefinedRiskRating = src.ClientDefinedRiskRating
+		} else if *src.ClientDefinedRiskRating != *dst.ClientDefinedRiskRating {
+			kept, discarded := *dst.ClientDefinedRiskRating, *src.ClientDefinedRiskRating
+			if discarded.Compare(kept) > 0 {
+				kept, discarded = discarded, kept
+			}
+			dst.ClientDefinedRiskRating = &kept
+			m.conflict(dst.Name, "client_defined_risk_rating", kept.String(), discarded.String(), source)
+		}
+	}
+
+	if src.CvssVector != nil && *src.CvssVector != "" {
+		if dst.CvssVector == nil || *dst.CvssVector == "" {
+			dst.CvssVector = src.CvssVector
+		} else if !sameCVSS(*dst.CvssVector, *src.CvssVector) {
+			m.conflict(dst.Name, "cvss_vector", *dst.CvssVector, *src.CvssVector, source)
+		}
+	}
+
+	if src.Status != "" {
+		if dst.Status == "" {
+			dst.Status = src.Status
+		} else if !strings.EqualFold(src.Status, dst.Status) {
+			m.conflict(dst.Name, "status", dst.Status, src.Status, source)
+		}
+	}
+
+	if srcId, ok := src.NessusId.Int64(); ok && srcId != 0 {
+		if dstId, ok := dst.NessusId.Int64(); !ok || dstId == 0 {
+			dst.NessusId = src.NessusId
+		} else if srcId != dstId {
+			m.conflict(dst.Name, "nessus_id", dst.NessusId.String(), src.NessusId.String(), source)
+		}
+	}
+
+	if !dst.Id.IsSet() {
+		dst.Id = src.Id
+	}
+	if dst.Finding == "" {
+		dst.Finding = src.Finding
+	}
+
+	// The earliest confirmation date wins
+	if src.ConfirmedAt != "" && (dst.ConfirmedAt == "" || src.ConfirmedAt < dst.ConfirmedAt) {
+		dst.ConfirmedAt = src.ConfirmedAt
+	}
+
+	if src.ExploitAvailable != nil && (dst.ExploitAvailable == nil || *src.ExploitAvailable) {
+		dst.ExploitAvailable = src.ExploitAvailable
+	}
+
+	fillString(&dst.Summary, src.Summary)
+	fillString(&dst.Recommendation, src.Recommendation)
+	fillString(&dst.Assignee, src.Assignee)
+	fillString(&dst.OwaspId, src.OwaspId)
+	fillString(&dst.PublishedAt, src.PublishedAt)
+	fillString(&dst.Rapid7Id, src.Rapid7Id)
+	fillString(&dst.RemediatedAt, src.RemediatedAt)
+	fillString(&dst.SuppressUntil, src.SuppressUntil)
+	fillBool(&dst.SuppressForProject, src.SuppressForProject)
+	fillBool(&dst.SuppressOnAllProjects, src.SuppressOnAllProjects)
+	if dst.Assignees == nil {
+		dst.Assignees = src.Assignees
+	}
+	dst.Extra = copyExtra(dst.Extra, src.Extra)
+}
+
+// addHosts unions the hosts of src into the merged issue, filling in empty
+// fields of hosts it already has, and files its technical details under them
+func (merged *mergedIssue) addHosts(src *Issue) {
+	keys := make([]HostKey, 0, len(src.AffectedHosts))
+	for _, host := range src.AffectedHosts {
+		key := host.Key()
+		keys = append(keys, key)
+
+		if existing, ok := merged.hosts.Get(key); ok {
+			merged.hosts.Set(mergeHost(existing, host))
+		} else {
+			merged.hosts.Add(host)
+		}
+	}
+
+	if strings.TrimSpace(src.TechnicalDetails) == "" {
+		return
+	}
+	for i := range merged.details {
+		if merged.details[i].details == src.TechnicalDetails {
+			merged.details[i].hosts = append(merged.details[i].hosts, keys...)
+			return
+		}
+	}
+	merged.details = append(merged.details, detailsBlock{details: src.TechnicalDetails, hosts: keys})
+}
+
+// technicalDetails returns the details as they are when every source agreed,
+// and otherwise each distinct text under a heading naming its hosts
+func (merged *mergedIssue) technicalDetails() string {
+	switch len(merged.details) {
+	case 0:
+		return merged.issue.TechnicalDetails
+	case 1:
+		return merged.details[0].details
+	}
+
+	var b strings.Builder
+	for _, block := range merged.details {
+		if len(block.hosts) > 0 {
+			seen := make(map[HostKey]bool)
+			var names []string
+			for _, key := range block.hosts {
+				if !seen[key] {
+					seen[key] = true
+					names = append(names, html.EscapeString(key.String()))
+				}
+			}
+			b.WriteString("<p><strong>Affected hosts: " + strings.Join(names, ", ") + "</strong></p>")
+		}
+		b.WriteString(block.details)
+	}
+	return b.String()
+}
+
+// mergeHost fills the empty fields of dst from src
+func mergeHost(dst, src AffectedHost) AffectedHost {
+	if dst.Hostname == "" {
+		dst.Hostname = src.Hostname
+	}
+	if src.Cpes != nil {
+		var cpes []string
+		if dst.Cpes != nil {
+			cpes = append(cpes, *dst.Cpes...)
+		}
+		cpes = uniqueStrings(cpes, *src.Cpes, nil)
+		dst.Cpes = &cpes
+	}
+	fillString(&dst.Location, src.Location)
+	fillString(&dst.Name, src.Name)
+	fillString(&dst.OperatingSystem, src.OperatingSystem)
+	fillString(&dst.Service, src.Service)
+	fillString(&dst.Status, src.Status)
+	fillString(&dst.SuppressUntil, src.SuppressUntil)
+	fillBool(&dst.SuppressAllProjects, src.SuppressAllProjects)
+	fillBool(&dst.SuppressProject, src.SuppressProject)
+	dst.Extra = copyExtra(dst.Extra, src.Extra)
+	return dst
+}
+
+// sameCVSS compares two vectors after normalising them, falling back to the
+// raw strings when either does not parse
+func sameCVSS(a, b string) bool {
+	ca, errA := ParseCVSS(a)
+	cb, errB := ParseCVSS(b)
+	if errA != nil || errB != nil {
+		return strings.TrimRight(a, "/") == strings.TrimRight(b, "/")
+	}
+	return ca.Vector == cb.Vector
+}
+
+func fillString(dst **string, src *string) {
+	if (*dst == nil || **dst == "") && src != nil && *src != "" {
+		*dst = src
+	}
+}
+
+func fillBool(dst **bool, src *bool) {
+	if *dst == nil {
+		*dst = src
+	}
+}
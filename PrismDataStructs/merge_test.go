@@ -0,0 +1,80 @@
+package PrismDataStructs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	cveA, cveB := []string{"CVE-2021-1"}, []string{"cve-2021-1", "CVE-2021-2"}
+	nessus := Issue{
+		Name:               "SSL Certificate Expired",
+		NessusId:           NewNessusId(15901),
+		OriginalRiskRating: RiskMedium,
+		Cves:               &cveA,
+		References:         []string{"https://example.com/a"},
+		TechnicalDetails:   "<p>expired</p>",
+		AffectedHosts: []AffectedHost{
+			{Ip: "10.0.0.1", Port: NewPort(443)},
+			{Ip: "10.0.0.2", Port: NewPort(443)},
+		},
+	}
+	nuclei := Issue{
+		Name:               "ssl certificate expired",
+		OriginalRiskRating: RiskHigh,
+		Cves:               &cveB,
+		References:         []string{"https://example.com/a", "https://templates.nuclei.sh/templates/expired-ssl"},
+		TechnicalDetails:   "<p>not after 2020</p>",
+		AffectedHosts: []AffectedHost{
+			{Ip: "10.0.0.1", Port: NewPort(443), Hostname: "web01"},
+			{Ip: "10.0.0.1", Port: NewPort(8443)},
+		},
+	}
+	other := Issue{Name: "Other", OriginalRiskRating: RiskLow}
+
+	merger := NewMerger()
+	merger.AddIssue("nessus.json", &nessus)
+	merger.AddIssue("nuclei.json", &nuclei)
+	merger.AddIssue("nuclei.json", &other)
+	prism := merger.Prism()
+
+	if len(prism.Issues) != 2 {
+		t.Fatalf("merged into %d issues, want 2", len(prism.Issues))
+	}
+	merged := prism.Issues[0]
+	if merged.OriginalRiskRating != RiskHigh {
+		t.Errorf("rating = %s, want the most severe", merged.OriginalRiskRating)
+	}
+	if len(merged.AffectedHosts) != 4 {
+		t.Errorf("%d affected hosts, want 4", len(merged.AffectedHosts))
+	}
+	if len(merged.References) != 2 || len(*merged.Cves) != 2 {
+		t.Errorf("references %v, cves %v", merged.References, *merged.Cves)
+	}
+	if !strings.Contains(merged.TechnicalDetails, "<p>expired</p>") || !strings.Contains(merged.TechnicalDetails, "<p>not after 2020</p>") ||
+		!strings.Contains(merged.TechnicalDetails, "10.0.0.1:8443/tcp</strong>") {
+		t.Errorf("technical details = %s", merged.TechnicalDetails)
+	}
+
+	conflicts := merger.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Field != "original_risk_rating" || conflicts[0].Discarded != "Medium" {
+		t.Errorf("conflicts = %v", conflicts)
+	}
+
+	// The nuclei template identity now finds the merged issue
+	renamed := Issue{Name: "Expired cert", References: []string{"https://github.com/projectdiscovery/nuclei-templates/blob/main/ssl/expired-ssl.yaml"}}
+	merger.AddIssue("manual.json", &renamed)
+	if len(merger.Prism().Issues) != 2 {
+		t.Error("issues sharing a nuclei template should merge")
+	}
+}
+
+func TestParseIssueIdentities(t *testing.T) {
+	identities, err := ParseIssueIdentities("name, CVES")
+	if err != nil || len(identities) != 2 || identities[1] != IdentityCves {
+		t.Errorf("ParseIssueIdentities = %v, %v", identities, err)
+	}
+	if _, err := ParseIssueIdentities("plugin"); err == nil {
+		t.Error("unknown identities should fail")
+	}
+}
@@ -0,0 +1,248 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// CurrentVersion is the Prism schema version described by this package
+const CurrentVersion int64 = 1
+
+// UnversionedVersion is reported for old exports that have no version key
+const UnversionedVersion int64 = 0
+
+// ErrUnknownVersion is returned for files whose version has no migration path
+// to or from CurrentVersion
+var ErrUnknownVersion = errors.New("unknown prism schema version")
+
+// Migration converts a Prism file between two adjacent schema versions. From
+// is lower than To for an upgrade and higher for a downgrade. Both hooks work
+// on the generic JSON form, with numbers decoded as json.Number.
+type Migration struct {
+	From int64
+	To   int64
+
+	// Header rewrites the top level object, without its "issues" key
+	Header func(header map[string]interface{}) error
+
+	// Issue rewrites a single issue object
+	Issue func(issue map[string]interface{}) error
+}
+
+var migrations = make(map[[2]int64]Migration)
+
+// RegisterMigration adds m to the registry. It panics if m does not step
+// between adjacent versions or if a migration for the same step exists.
+func RegisterMigration(m Migration) {
+	if m.To-m.From != 1 && m.From-m.To != 1 {
+		panic(fmt.Sprintf("prism migration %d -> %d must step by one version", m.From, m.To))
+	}
+
+	key := [2]int64{m.From, m.To}
+	if _, ok := migrations[key]; ok {
+		panic(fmt.Sprintf("prism migration %d -> %d registered twice", m.From, m.To))
+	}
+	migrations[key] = m
+}
+
+func init() {
+	// Exports from before Prism versioned its files are otherwise identical
+	// to version 1
+	RegisterMigration(Migration{
+		From: UnversionedVersion,
+		To:   1,
+		Header: func(header map[string]interface{}) error {
+			header["version"] = json.Number("1")
+			return nil
+		},
+	})
+	RegisterMigration(Migration{
+		From: 1,
+		To:   UnversionedVersion,
+		Header: func(header map[string]interface{}) error {
+			delete(header, "version")
+			return nil
+		},
+	})
+}
+
+// migrationPath returns the migrations that take a file from one version to another
+func migrationPath(from, to int64) ([]Migration, error) {
+	var path []Migration
+	for version := from; version != to; {
+		next := version + 1
+		if to < from {
+			next = version - 1
+		}
+
+		m, ok := migrations[[2]int64{version, next}]
+		if !ok {
+			return nil, fmt.Errorf("%w %d: no migration from version %d to %d", ErrUnknownVersion, from, version, next)
+		}
+		path = append(path, m)
+		version = next
+	}
+	return path, nil
+}
+
+// DetectVersion returns the schema version of the Prism file in data. A
+// missing or null version key means UnversionedVersion.
+func DetectVersion(data []byte) (int64, error) {
+	var header struct {
+		Version json.RawMessage `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	return parseVersion(header.Version)
+}
+
+func parseVersion(raw json.RawMessage) (int64, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return UnversionedVersion, nil
+	}
+
+	text := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return 0, err
+		}
+	}
+
+	version, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: version %s is not a whole number", ErrUnknownVersion, raw)
+	}
+	return version, nil
+}
+
+// CheckVersion returns an ErrUnknownVersion error unless files of the given
+// version can be migrated to CurrentVersion
+func CheckVersion(version int64) error {
+	_, err := migrationPath(version, CurrentVersion)
+	return err
+}
+
+// migrate runs the migrations between two versions over a top level document
+func migrate(doc map[string]json.RawMessage, from, to int64) (map[string]json.RawMessage, error) {
+	path, err := migrationPath(from, to)
+	if err != nil || len(path) == 0 {
+		return doc, err
+	}
+
+	header := make(map[string]json.RawMessage, len(doc))
+	for key, raw := range doc {
+		if key != "issues" {
+			header[key] = raw
+		}
+	}
+	if header, err = migrateHeader(header, path); err != nil {
+		return nil, err
+	}
+
+	raw, ok := doc["issues"]
+	if !ok {
+		return header, nil
+	}
+
+	var rawIssues []json.RawMessage
+	if err = json.Unmarshal(raw, &rawIssues); err != nil {
+		return nil, fmt.Errorf("issues: %w", err)
+	}
+	if rawIssues == nil {
+		header["issues"] = raw
+		return header, nil
+	}
+
+	for issueIndex := range rawIssues {
+		if rawIssues[issueIndex], err = migrateIssue(rawIssues[issueIndex], path); err != nil {
+			return nil, fmt.Errorf("issues[%d]: %w", issueIndex, err)
+		}
+	}
+	if header["issues"], err = marshalUnescaped(rawIssues); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// migrateHeader runs the Header hooks of path over the top level keys of a file
+func migrateHeader(doc map[string]json.RawMessage, path []Migration) (map[string]json.RawMessage, error) {
+	header := make(map[string]interface{}, len(doc))
+	for key, raw := range doc {
+		value, err := decodeGeneric(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		header[key] = value
+	}
+
+	for _, m := range path {
+		if m.Header == nil {
+			continue
+		}
+		if err := m.Header(header); err != nil {
+			return nil, fmt.Errorf("migrating version %d to %d: %w", m.From, m.To, err)
+		}
+	}
+
+	out := make(map[string]json.RawMessage, len(header))
+	for key, value := range header {
+		raw, err := marshalUnescaped(value)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = raw
+	}
+	return out, nil
+}
+
+// migrateIssue runs the Issue hooks of path over a single raw issue
+func migrateIssue(raw json.RawMessage, path []Migration) (json.RawMessage, error) {
+	if !hasIssueHooks(path) {
+		return raw, nil
+	}
+
+	value, err := decodeGeneric(raw)
+	if err != nil {
+		return nil, err
+	}
+	issue, ok := value.(map[string]interface{})
+	if !ok {
+		return raw, nil
+	}
+
+	for _, m := range path {
+		if m.Issue == nil {
+			continue
+		}
+		if err = m.Issue(issue); err != nil {
+			return nil, fmt.Errorf("migrating version %d to %d: %w", m.From, m.To, err)
+		}
+	}
+	return marshalUnescaped(issue)
+}
+
+// hasIssueHooks reports whether any step of path rewrites issues
+func hasIssueHooks(path []Migration) bool {
+	for _, m := range path {
+		if m.Issue != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeGeneric decodes raw JSON keeping numbers as json.Number so large IDs
+// are not rounded through float64
+func decodeGeneric(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value interface{}
+	err := dec.Decode(&value)
+	return value, err
+}
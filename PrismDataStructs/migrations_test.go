@@ -0,0 +1,57 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadUpgradesUnversionedFile(t *testing.T) {
+	prism, err := LoadFile(filepath.Join("testdata", "unversioned.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prism.Version != CurrentVersion {
+		t.Errorf("version = %d, want %d", prism.Version, CurrentVersion)
+	}
+	if got := prism.Issues[0].NessusId.String(); got != "42873" {
+		t.Errorf("nessus_id = %q after migration, want 42873", got)
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	for _, input := range []string{`{"issues":[],"version":99}`, `{"issues":[],"version":-3}`, `{"version":"two"}`} {
+		_, err := Load(strings.NewReader(input))
+		if !errors.Is(err, ErrUnknownVersion) {
+			t.Errorf("%s: err = %v, want ErrUnknownVersion", input, err)
+		}
+	}
+}
+
+func TestSaveDowngrades(t *testing.T) {
+	prism, err := LoadFile(filepath.Join("testdata", "nessus-id-number.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := UnversionedVersion
+	var out bytes.Buffer
+	if err = prism.Save(&out, SaveOptions{TargetVersion: &target}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `"version"`) {
+		t.Errorf("downgraded file still has a version key:\n%s", out.String())
+	}
+
+	version, err := DetectVersion(out.Bytes())
+	if err != nil || version != UnversionedVersion {
+		t.Errorf("DetectVersion = %d, %v, want %d", version, err, UnversionedVersion)
+	}
+
+	unknown := int64(42)
+	if err = prism.Save(&out, SaveOptions{TargetVersion: &unknown}); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("downgrading to %d: err = %v, want ErrUnknownVersion", unknown, err)
+	}
+}
@@ -1,10 +1,16 @@
-// Just to stop erroring
+// Package PrismDataStructs holds the Prism JSON model shared by every tool in
+// PrismTools, along with helpers to load and save Prism files.
 package PrismDataStructs
 
+import "encoding/json"
+
 type Prism struct {
 	Issues  []Issue `json:"issues"`
 	Version int64   `json:"version"`
 	Phase   Phase   `json:"phase"`
+
+	// Extra holds keys this model does not know about so they survive a round trip
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type Phase struct {
@@ -22,22 +28,24 @@ type Phase struct {
 	Status           string       `json:"status"`
 	TestType         string       `json:"test_type"`
 	Tester           string       `json:"tester"`
+
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type Issue struct {
 	AffectedHosts           []AffectedHost `json:"affected_hosts"`
 	Assignee                *string        `json:"assignee"`
 	Assignees               *[]string      `json:"assignees"`
-	ClientDefinedRiskRating *string        `json:"client_defined_risk_rating"`
+	ClientDefinedRiskRating *RiskRating    `json:"client_defined_risk_rating"`
 	ConfirmedAt             string         `json:"confirmed_at"`
 	Cves                    *[]string      `json:"cves"`
 	CvssVector              *string        `json:"cvss_vector"`
 	ExploitAvailable        *bool          `json:"exploit_available"`
 	Finding                 string         `json:"finding"`
-	Id                      *int64         `json:"id"`
+	Id                      Id             `json:"id"`
 	Name                    string         `json:"name"`
-	NessusId                *int           `json:"nessus_id"`
-	OriginalRiskRating      string         `json:"original_risk_rating"`
+	NessusId                NessusId       `json:"nessus_id"`
+	OriginalRiskRating      RiskRating     `json:"original_risk_rating"`
 	OwaspId                 *string        `json:"owasp_id"`
 	PublishedAt             *string        `json:"published_at"`
 	Rapid7Id                *string        `json:"rapid7_id"`
@@ -50,6 +58,8 @@ type Issue struct {
 	SuppressOnAllProjects   *bool          `json:"suppress_on_all_projects"`
 	SuppressUntil           *string        `json:"suppress_until"`
 	TechnicalDetails        string         `json:"technical_details"`
+
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type AffectedHost struct {
@@ -59,11 +69,13 @@ type AffectedHost struct {
 	Location            *string   `json:"location"`
 	Name                *string   `json:"name"`
 	OperatingSystem     *string   `json:"operating_system"`
-	Port                *int      `json:"port"`
+	Port                Port      `json:"port"`
 	Protocol            *string   `json:"protocol"`
 	Service             *string   `json:"service"`
 	Status              *string   `json:"status"`
 	SuppressAllProjects *bool     `json:"suppress_all_projects"`
 	SuppressProject     *bool     `json:"suppress_project"`
 	SuppressUntil       *string   `json:"suppress_until"`
+
+	Extra map[string]json.RawMessage `json:"-"`
 }
@@ -0,0 +1,130 @@
+package PrismDataStructs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SaveOptions controls how a Prism file is written
+type SaveOptions struct {
+	// Indent is the per-level indentation, an empty string writes compact JSON
+	Indent string
+
+	// EscapeHTML escapes <, > and & inside strings, like json.Marshal does
+	EscapeHTML bool
+
+	// NumericForm is the canonical form for nessus_id, port and id values,
+	// FormPreserve writes them back exactly as they were read
+	NumericForm NumericForm
+
+	// TargetVersion downgrades the file to an older schema version when set,
+	// nil writes CurrentVersion
+	TargetVersion *int64
+}
+
+// DefaultSaveOptions matches the output the tools have always produced
+var DefaultSaveOptions = SaveOptions{
+	Indent:     "  ",
+	EscapeHTML: true,
+}
+
+// Load decodes a Prism file from r, upgrading older schema versions to
+// CurrentVersion. The whole file is held in memory; use NewReader for files
+// too large for that.
+func Load(r io.Reader) (*Prism, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := DetectVersion(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding prism file: %w", err)
+	}
+
+	if version != CurrentVersion {
+		var doc map[string]json.RawMessage
+		if err = json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decoding prism file: %w", err)
+		}
+		if doc, err = migrate(doc, version, CurrentVersion); err != nil {
+			return nil, err
+		}
+		if data, err = marshalUnescaped(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var prism Prism
+	if err = json.Unmarshal(data, &prism); err != nil {
+		return nil, fmt.Errorf("decoding prism file: %w", err)
+	}
+	return &prism, nil
+}
+
+// LoadFile opens and decodes the Prism file at path
+func LoadFile(path string) (*Prism, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	prism, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return prism, nil
+}
+
+// Save encodes the Prism file to w. A NumericForm other than FormPreserve is
+// applied to the project before it is encoded.
+func (p *Prism) Save(w io.Writer, opts SaveOptions) error {
+	if opts.NumericForm != FormPreserve {
+		p.SetNumericForm(opts.NumericForm)
+	}
+
+	var out interface{} = p
+	if opts.TargetVersion != nil && *opts.TargetVersion != CurrentVersion {
+		doc, err := p.downgrade(*opts.TargetVersion)
+		if err != nil {
+			return err
+		}
+		out = doc
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", opts.Indent)
+	enc.SetEscapeHTML(opts.EscapeHTML)
+	return enc.Encode(out)
+}
+
+// SaveFile creates (or truncates) path and writes the Prism file to it
+func (p *Prism) SaveFile(path string, opts SaveOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = p.Save(f, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// downgrade returns the project as a top level document in an older version
+func (p *Prism) downgrade(version int64) (map[string]json.RawMessage, error) {
+	data, err := marshalUnescaped(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]json.RawMessage
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return migrate(doc, CurrentVersion, version)
+}
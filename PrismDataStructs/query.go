@@ -0,0 +1,639 @@
+package PrismDataStructs
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A Query is a filter expression over issues and their affected hosts, such
+// as
+//
+//	rating >= High and ip in 10.1.0.0/16 and has cve
+//	name ~ "(?i)tls|ssl" and not port in [80, 8000-8999]
+//
+// Expressions combine comparisons with and, or, not and parentheses. The
+// operators are = and != (case-insensitive for text), <, <=, > and >= for
+// ratings and numbers, ~ and !~ for regular expressions, in for lists,
+// port ranges and CIDR blocks, and has for fields that are set. Fields with
+// several values, like cve, match when any value does.
+//
+// Host fields (ip, hostname, host, port, protocol, service, os) are checked
+// one affected host at a time: an issue matches when the expression holds
+// for at least one of its hosts, and Filter keeps only those hosts.
+type Query struct {
+	source string
+	root   queryNode
+	hosts  bool
+}
+
+type fieldKind int
+
+const (
+	fieldText fieldKind = iota
+	fieldRating
+	fieldNumber
+	fieldBool
+	fieldIP
+)
+
+// queryField reads one field from an issue, or from the host being checked
+// when host is set
+type queryField struct {
+	name string
+	kind fieldKind
+	host bool
+
+	// values returns the field as text; more than one value for lists
+	values func(i *Issue, h *AffectedHost) []string
+}
+
+var queryFields = map[string]*queryField{}
+
+func init() {
+	text := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return []string{s}
+	}
+	pointer := func(s *string) []string {
+		if s == nil {
+			return nil
+		}
+		return text(*s)
+	}
+	number := func(n tolerantNumber) []string {
+		return text(n.String())
+	}
+
+	issueFields := []queryField{
+		{"name", fieldText, false, func(i *Issue, h *AffectedHost) []string { return text(i.Name) }},
+		{"finding", fieldText, false, func(i *Issue, h *AffectedHost) []string { return text(i.Finding) }},
+		{"summary", fieldText, false, func(i *Issue, h *AffectedHost) []string { return pointer(i.Summary) }},
+		{"recommendation", fieldText, false, func(i *Issue, h *AffectedHost) []string { return pointer(i.Recommendation) }},
+		{"details", fieldText, false, func(i *Issue, h *AffectedHost) []string { return text(i.TechnicalDetails) }},
+		{"status", fieldText, false, func(i *Issue, h *AffectedHost) []string { return text(i.Status) }},
+		{"rating", fieldRating, false, func(i *Issue, h *AffectedHost) []string { return text(string(i.OriginalRiskRating)) }},
+		{"client_rating", fieldRating, false, func(i *Issue, h *AffectedHost) []string {
+			if i.ClientDefinedRiskRating == nil {
+				return nil
+			}
+			return text(string(*i.ClientDefinedRiskRating))
+		}},
+		{"cve", fieldText, false, func(i *Issue, h *AffectedHost) []string {
+			if i.Cves == nil {
+				return nil
+			}
+			return *i.Cves
+		}},
+		{"reference", fieldText, false, func(i *Issue, h *AffectedHost) []string { return i.References }},
+		{"cvss", fieldNumber, false, func(i *Issue, h *AffectedHost) []string {
+			cvss, err := i.CVSS()
+			if err != nil || !cvss.Scored {
+				return nil
+			}
+			return []string{strconv.FormatFloat(cvss.BaseScore, 'f', 1, 64)}
+		}},
+		{"cvss_vector", fieldText, false, func(i *Issue, h *AffectedHost) []string { return pointer(i.CvssVector) }},
+		{"nessus_id", fieldNumber, false, func(i *Issue, h *AffectedHost) []string { return number(i.NessusId.tolerantNumber) }},
+		{"exploit", fieldBool, false, func(i *Issue, h *AffectedHost) []string {
+			if i.ExploitAvailable == nil {
+				return nil
+			}
+			return []string{strconv.FormatBool(*i.ExploitAvailable)}
+		}},
+		{"confirmed_at", fieldText, false, func(i *Issue, h *AffectedHost) []string { return text(i.ConfirmedAt) }},
+		{"owasp_id", fieldText, false, func(i *Issue, h *AffectedHost) []string { return pointer(i.OwaspId) }},
+		{"rapid7_id", fieldText, false, func(i *Issue, h *AffectedHost) []string { return pointer(i.Rapid7Id) }},
+	}
+
+	hostFields := []queryField{
+		{"ip", fieldIP, true, func(i *Issue, h *AffectedHost) []string { return text(h.Key().IP) }},
+		{"hostname", fieldText, true, func(i *Issue, h *AffectedHost) []string { return text(h.Key().Hostname) }},
+		{"host", fieldText, true, func(i *Issue, h *AffectedHost) []string { return text(h.Key().Address()) }},
+		{"port", fieldNumber, true, func(i *Issue, h *AffectedHost) []string { return number(h.Port.tolerantNumber) }},
+		{"protocol", fieldText, true, func(i *Issue, h *AffectedHost) []string { return text(h.Key().Protocol) }},
+		{"service", fieldText, true, func(i *Issue, h *AffectedHost) []string { return pointer(h.Service) }},
+		{"os", fieldText, true, func(i *Issue, h *AffectedHost) []string { return pointer(h.OperatingSystem) }},
+	}
+
+	for _, fields := range [][]queryField{issueFields, hostFields} {
+		for i := range fields {
+			queryFields[fields[i].name] = &fields[i]
+		}
+	}
+	queryFields["severity"] = queryFields["rating"]
+	queryFields["cves"] = queryFields["cve"]
+}
+
+// QueryFields returns the names of the fields a query can use
+func QueryFields() []string {
+	names := make([]string, 0, len(queryFields))
+	for name := range queryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseQuery compiles a query expression
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return &Query{source: s, root: root, hosts: root.usesHost()}, nil
+}
+
+// String returns the expression the query was parsed from
+func (q *Query) String() string {
+	return q.source
+}
+
+// Match reports whether the issue matches, on at least one of its hosts if
+// the query uses host fields
+func (q *Query) Match(issue *Issue) bool {
+	_, ok := q.Filter(issue)
+	return ok
+}
+
+// Filter returns the issue if it matches, narrowed to the affected hosts
+// that matched when the query uses host fields. The issue is not modified.
+func (q *Query) Filter(issue *Issue) (*Issue, bool) {
+	if !q.hosts || len(issue.AffectedHosts) == 0 {
+		return issue, q.root.eval(issue, nil)
+	}
+
+	var hosts []AffectedHost
+	for i := range issue.AffectedHosts {
+		if q.root.eval(issue, &issue.AffectedHosts[i]) {
+			hosts = append(hosts, issue.AffectedHosts[i])
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, false
+	}
+
+	filtered := *issue
+	filtered.AffectedHosts = hosts
+	return &filtered, true
+}
+
+// Evaluation
+
+type queryNode interface {
+	eval(issue *Issue, host *AffectedHost) bool
+	usesHost() bool
+}
+
+type andNode struct{ left, right queryNode }
+type orNode struct{ left, right queryNode }
+type notNode struct{ operand queryNode }
+
+func (n andNode) eval(i *Issue, h *AffectedHost) bool { return n.left.eval(i, h) && n.right.eval(i, h) }
+func (n andNode) usesHost() bool                      { return n.left.usesHost() || n.right.usesHost() }
+func (n orNode) eval(i *Issue, h *AffectedHost) bool  { return n.left.eval(i, h) || n.right.eval(i, h) }
+func (n orNode) usesHost() bool                       { return n.left.usesHost() || n.right.usesHost() }
+func (n notNode) eval(i *Issue, h *AffectedHost) bool { return !n.operand.eval(i, h) }
+func (n notNode) usesHost() bool                      { return n.operand.usesHost() }
+
+// numberRange is an inclusive range, a single number when low == high
+type numberRange struct{ low, high float64 }
+
+// comparison is one field test. The operand is compiled for the field kind
+// when the query is parsed.
+type comparison struct {
+	field *queryField
+	op    string
+
+	texts    []string
+	pattern  *regexp.Regexp
+	ranges   []numberRange
+	prefixes []netip.Prefix
+	rank     int
+}
+
+func (c *comparison) usesHost() bool { return c.field.host }
+
+func (c *comparison) eval(issue *Issue, host *AffectedHost) bool {
+	if c.field.host && host == nil {
+		return false
+	}
+	values := c.field.values(issue, host)
+	if c.op == "has" {
+		return len(values) > 0
+	}
+
+	for _, value := range values {
+		if c.test(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparison) test(value string) bool {
+	if c.op == "~" {
+		return c.pattern.MatchString(value)
+	}
+
+	switch c.field.kind {
+	case fieldRating:
+		rank := RiskRating(value).Rank()
+		if rating, err := ParseRiskRating(value); err == nil {
+			rank = rating.Rank()
+		}
+		switch c.op {
+		case "<":
+			return rank < c.rank
+		case "<=":
+			return rank <= c.rank
+		case ">":
+			return rank > c.rank
+		case ">=":
+			return rank >= c.rank
+		}
+	case fieldNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		switch c.op {
+		case "<":
+			return n < c.ranges[0].low
+		case "<=":
+			return n <= c.ranges[0].low
+		case ">":
+			return n > c.ranges[0].low
+		case ">=":
+			return n >= c.ranges[0].low
+		}
+		for _, r := range c.ranges {
+			if n >= r.low && n <= r.high {
+				return true
+			}
+		}
+		return false
+	case fieldIP:
+		if len(c.prefixes) > 0 {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return false
+			}
+			for _, prefix := range c.prefixes {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	for _, text := range c.texts {
+		if strings.EqualFold(value, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parsing
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokenWord && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, args ...interface{}) error {
+	return fmt.Errorf("query: %s at offset %d", fmt.Sprintf(format, args...), tok.offset)
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.keyword("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.peek()
+	if tok.kind == tokenLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, "expected \")\"")
+		}
+		return node, nil
+	}
+
+	if p.keyword("has") {
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		return &comparison{field: field, op: "has"}, nil
+	}
+
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+
+	opTok := p.next()
+	op := opTok.text
+	if opTok.kind == tokenWord && strings.EqualFold(op, "in") {
+		op = "in"
+	} else if opTok.kind != tokenOperator {
+		return nil, p.errorf(opTok, "expected an operator after %s", field.name)
+	}
+
+	var operands []queryToken
+	if op == "in" && p.peek().kind == tokenLBracket {
+		p.next()
+		for {
+			value := p.next()
+			if value.kind != tokenWord && value.kind != tokenString {
+				return nil, p.errorf(value, "expected a value in the list for %s", field.name)
+			}
+			operands = append(operands, value)
+
+			sep := p.next()
+			if sep.kind == tokenRBracket {
+				break
+			}
+			if sep.kind != tokenComma {
+				return nil, p.errorf(sep, "expected \",\" or \"]\"")
+			}
+		}
+	} else {
+		value := p.next()
+		if value.kind != tokenWord && value.kind != tokenString {
+			return nil, p.errorf(value, "expected a value after %s %s", field.name, op)
+		}
+		operands = append(operands, value)
+	}
+
+	node, err := compileComparison(field, op, operands)
+	if err != nil {
+		return nil, p.errorf(opTok, "%v", err)
+	}
+	return node, nil
+}
+
+func (p *queryParser) parseField() (*queryField, error) {
+	tok := p.next()
+	if tok.kind != tokenWord {
+		return nil, p.errorf(tok, "expected a field name")
+	}
+	field, ok := queryFields[strings.ToLower(tok.text)]
+	if !ok {
+		return nil, p.errorf(tok, "unknown field %q (fields are %s)", tok.text, strings.Join(QueryFields(), ", "))
+	}
+	return field, nil
+}
+
+// compileComparison checks the operator suits the field and parses the
+// operands for it. != and !~ are compiled as not = and not ~.
+func compileComparison(field *queryField, op string, operands []queryToken) (queryNode, error) {
+	negate := false
+	switch op {
+	case "!=":
+		op, negate = "=", true
+	case "!~":
+		op, negate = "~", true
+	}
+
+	c := &comparison{field: field, op: op}
+	switch op {
+	case "~":
+		pattern, err := regexp.Compile(operands[0].text)
+		if err != nil {
+			return nil, err
+		}
+		c.pattern = pattern
+	case "<", "<=", ">", ">=":
+		switch field.kind {
+		case fieldRating:
+			rating, err := ParseRiskRating(operands[0].text)
+			if err != nil {
+				return nil, err
+			}
+			c.rank = rating.Rank()
+		case fieldNumber:
+			n, err := strconv.ParseFloat(operands[0].text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s needs a number, not %q", field.name, operands[0].text)
+			}
+			c.ranges = []numberRange{{n, n}}
+		default:
+			return nil, fmt.Errorf("%s cannot be compared with %s", field.name, op)
+		}
+	case "=", "in":
+		if op == "=" && len(operands) != 1 {
+			return nil, fmt.Errorf("= takes a single value")
+		}
+		for _, operand := range operands {
+			if err := c.addOperand(operand.text, op == "in"); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+
+	if negate {
+		return notNode{c}, nil
+	}
+	return c, nil
+}
+
+func (c *comparison) addOperand(text string, ranges bool) error {
+	switch c.field.kind {
+	case fieldNumber:
+		low, high, isRange := strings.Cut(text, "-")
+		if !ranges || !isRange {
+			high = low
+		}
+		l, errLow := strconv.ParseFloat(strings.TrimSpace(low), 64)
+		h, errHigh := strconv.ParseFloat(strings.TrimSpace(high), 64)
+		if errLow != nil || errHigh != nil || h < l {
+			return fmt.Errorf("%s needs a number or range, not %q", c.field.name, text)
+		}
+		c.ranges = append(c.ranges, numberRange{l, h})
+	case fieldRating:
+		rating, err := ParseRiskRating(text)
+		if err != nil {
+			return err
+		}
+		c.texts = append(c.texts, string(rating))
+	case fieldBool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%s needs true or false, not %q", c.field.name, text)
+		}
+		c.texts = append(c.texts, strconv.FormatBool(b))
+	case fieldIP:
+		if prefix, err := netip.ParsePrefix(text); err == nil {
+			c.prefixes = append(c.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(text); err == nil {
+			c.prefixes = append(c.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			return fmt.Errorf("%s needs an address or CIDR block, not %q", c.field.name, text)
+		}
+	default:
+		c.texts = append(c.texts, text)
+	}
+	return nil
+}
+
+// Lexing
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+var punctuation = map[rune]tokenKind{
+	'(': tokenLParen,
+	')': tokenRParen,
+	'[': tokenLBracket,
+	']': tokenRBracket,
+	',': tokenComma,
+}
+
+type queryToken struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func lexQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case punctuation[r] != tokenEOF:
+			tokens = append(tokens, queryToken{punctuation[r], string(r), i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var b strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == r {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("query: unterminated string at offset %d", start)
+			}
+			i++
+			tokens = append(tokens, queryToken{tokenString, b.String(), start})
+		case strings.ContainsRune("=!<>~", r):
+			op := string(r)
+			if i+1 < len(runes) {
+				switch pair := string(runes[i : i+2]); pair {
+				case "==", "!=", "<=", ">=", "!~":
+					op = pair
+				}
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("query: unknown operator \"!\" at offset %d", i)
+			}
+
+			tokens = append(tokens, queryToken{tokenOperator, strings.Replace(op, "==", "=", 1), i})
+			i += len(op)
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()[],\"'=!<>~", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{tokenWord, string(runes[start:i]), start})
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEOF, offset: len(runes)}), nil
+}
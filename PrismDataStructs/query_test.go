@@ -0,0 +1,83 @@
+package PrismDataStructs
+
+import "testing"
+
+func TestQuery(t *testing.T) {
+	cves := []string{"CVE-2021-44228"}
+	vector := "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"
+	exploit := true
+	issues := []Issue{
+		{
+			Name: "Apache Log4j RCE", OriginalRiskRating: RiskCritical, Cves: &cves, CvssVector: &vector, ExploitAvailable: &exploit, Status: "open",
+			AffectedHosts: []AffectedHost{{Ip: "10.1.2.3", Port: NewPort(8080)}, {Ip: "10.2.0.1", Port: NewPort(443)}},
+		},
+		{
+			Name: "TLS Version 1.0 Protocol Detection", OriginalRiskRating: RiskMedium, Status: "open",
+			AffectedHosts: []AffectedHost{{Ip: "10.1.0.9", Port: NewPort(443)}},
+		},
+		{Name: "Missing security headers", OriginalRiskRating: RiskLow, Status: "remediated"},
+	}
+
+	tests := []struct {
+		query   string
+		matches []string
+		hosts   int
+	}{
+		{"rating >= High and ip in 10.1.0.0/16 and has cve", []string{"Apache Log4j RCE"}, 1},
+		{"severity in [medium, low]", []string{"TLS Version 1.0 Protocol Detection", "Missing security headers"}, 1},
+		{`name ~ "(?i)tls|ssl" or cve = cve-2021-44228`, []string{"Apache Log4j RCE", "TLS Version 1.0 Protocol Detection"}, 3},
+		{"port in [80, 8000-8999]", []string{"Apache Log4j RCE"}, 1},
+		{"not port = 443 and rating > low", []string{"Apache Log4j RCE"}, 1},
+		{"status != open", []string{"Missing security headers"}, 0},
+		{"cvss >= 9 and exploit = true", []string{"Apache Log4j RCE"}, 2},
+		{"(rating = low or rating = critical) and not has cve", []string{"Missing security headers"}, 0},
+	}
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", test.query, err)
+			continue
+		}
+
+		var matches []string
+		hosts := 0
+		for i := range issues {
+			if filtered, ok := q.Filter(&issues[i]); ok {
+				matches = append(matches, filtered.Name)
+				hosts += len(filtered.AffectedHosts)
+			}
+		}
+		if len(matches) != len(test.matches) || hosts != test.hosts {
+			t.Errorf("%q matched %v with %d hosts, want %v with %d", test.query, matches, hosts, test.matches, test.hosts)
+			continue
+		}
+		for i := range matches {
+			if matches[i] != test.matches[i] {
+				t.Errorf("%q matched %v, want %v", test.query, matches, test.matches)
+			}
+		}
+	}
+
+	if len(issues[0].AffectedHosts) != 2 {
+		t.Error("Filter should not modify the issue")
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"colour = red",
+		"rating >= extreme",
+		"port in 443-80",
+		"ip in 10.0.0.0/33",
+		"name ~ \"(\"",
+		"name = 'open",
+		"(rating = high",
+		"rating = high high",
+		"name < x",
+	} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) should fail", query)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package PrismDataStructs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RiskRating is a Prism risk rating. Ratings decoded from JSON are
+// normalised to the Prism spelling when they are recognised and kept as
+// written otherwise, so the validator can report them.
+type RiskRating string
+
+const (
+	RiskInfo     RiskRating = "Info"
+	RiskLow      RiskRating = "Low"
+	RiskMedium   RiskRating = "Medium"
+	RiskHigh     RiskRating = "High"
+	RiskCritical RiskRating = "Critical"
+)
+
+// RiskRatings lists the Prism ratings from most to least severe
+var RiskRatings = []RiskRating{RiskCritical, RiskHigh, RiskMedium, RiskLow, RiskInfo}
+
+var riskRatingRanks = map[RiskRating]int{
+	RiskInfo:     1,
+	RiskLow:      2,
+	RiskMedium:   3,
+	RiskHigh:     4,
+	RiskCritical: 5,
+}
+
+// riskRatingAliases maps the lower case vocabularies of the scanners we
+// import from (Nessus, nuclei, CVSS, Burp, ZAP, OpenVAS, Nexpose, Qualys,
+// Trivy and Grype) to Prism ratings
+var riskRatingAliases = map[string]RiskRating{
+	"critical":      RiskCritical,
+	"urgent":        RiskCritical,
+	"high":          RiskHigh,
+	"severe":        RiskHigh,
+	"serious":       RiskHigh,
+	"medium":        RiskMedium,
+	"med":           RiskMedium,
+	"moderate":      RiskMedium,
+	"low":           RiskLow,
+	"minimal":       RiskLow,
+	"info":          RiskInfo,
+	"information":   RiskInfo,
+	"informational": RiskInfo,
+	"none":          RiskInfo,
+	"log":           RiskInfo,
+	"negligible":    RiskInfo,
+}
+
+// ParseRiskRating converts a severity word from any supported scanner into a
+// Prism rating, ignoring case and surrounding space
+func ParseRiskRating(s string) (RiskRating, error) {
+	if rating, ok := riskRatingAliases[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return rating, nil
+	}
+	return "", fmt.Errorf("unknown risk rating %q", s)
+}
+
+// RiskRatingFromCVSS maps a CVSS base score to the rating for its severity band
+func RiskRatingFromCVSS(score float64) RiskRating {
+	switch {
+	case score >= 9.0:
+		return RiskCritical
+	case score >= 7.0:
+		return RiskHigh
+	case score >= 4.0:
+		return RiskMedium
+	case score > 0:
+		return RiskLow
+	}
+	return RiskInfo
+}
+
+// CVSSSeverity returns the CVSS qualitative severity for the rating, which
+// calls Info "None"
+func (r RiskRating) CVSSSeverity() string {
+	if r == RiskInfo {
+		return "None"
+	}
+	return string(r)
+}
+
+// Valid reports whether r is one of the Prism ratings
+func (r RiskRating) Valid() bool {
+	return riskRatingRanks[r] > 0
+}
+
+// Rank orders ratings from 1 (Info) to 5 (Critical), unknown ratings are 0
+func (r RiskRating) Rank() int {
+	return riskRatingRanks[r]
+}
+
+// Compare returns -1, 0 or 1 as r is less severe than, as severe as or more
+// severe than other
+func (r RiskRating) Compare(other RiskRating) int {
+	switch {
+	case r.Rank() < other.Rank():
+		return -1
+	case r.Rank() > other.Rank():
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether r is as severe as other or more
+func (r RiskRating) AtLeast(other RiskRating) bool {
+	return r.Compare(other) >= 0
+}
+
+func (r RiskRating) String() string {
+	return string(r)
+}
+
+func (r *RiskRating) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("risk rating: %w", err)
+	}
+
+	if rating, err := ParseRiskRating(s); err == nil {
+		*r = rating
+	} else {
+		*r = RiskRating(s)
+	}
+	return nil
+}
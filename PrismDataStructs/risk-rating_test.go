@@ -0,0 +1,69 @@
+package PrismDataStructs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRiskRating(t *testing.T) {
+	tests := map[string]RiskRating{
+		"Critical":      RiskCritical,
+		"high":          RiskHigh,
+		" MEDIUM ":      RiskMedium,
+		"Moderate":      RiskMedium,
+		"low":           RiskLow,
+		"info":          RiskInfo,
+		"Informational": RiskInfo,
+		"None":          RiskInfo,
+		"Log":           RiskInfo,
+	}
+	for input, want := range tests {
+		got, err := ParseRiskRating(input)
+		if err != nil || got != want {
+			t.Errorf("ParseRiskRating(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseRiskRating("unknown"); err == nil {
+		t.Error("ParseRiskRating(\"unknown\") should fail")
+	}
+}
+
+func TestRiskRatingOrdering(t *testing.T) {
+	for i := 0; i < len(RiskRatings)-1; i++ {
+		if RiskRatings[i].Compare(RiskRatings[i+1]) != 1 {
+			t.Errorf("%s should be more severe than %s", RiskRatings[i], RiskRatings[i+1])
+		}
+	}
+	if !RiskHigh.AtLeast(RiskHigh) || RiskMedium.AtLeast(RiskHigh) {
+		t.Error("AtLeast is wrong")
+	}
+	if RiskRating("Extreme").Valid() || RiskRating("Extreme").Rank() != 0 {
+		t.Error("unknown ratings should be invalid and rank lowest")
+	}
+}
+
+func TestRiskRatingCVSS(t *testing.T) {
+	tests := map[float64]RiskRating{0: RiskInfo, 0.1: RiskLow, 3.9: RiskLow, 4.0: RiskMedium, 7.5: RiskHigh, 9.8: RiskCritical}
+	for score, want := range tests {
+		if got := RiskRatingFromCVSS(score); got != want {
+			t.Errorf("RiskRatingFromCVSS(%v) = %s, want %s", score, got, want)
+		}
+	}
+	if RiskInfo.CVSSSeverity() != "None" || RiskHigh.CVSSSeverity() != "High" {
+		t.Error("CVSSSeverity is wrong")
+	}
+}
+
+func TestRiskRatingJSON(t *testing.T) {
+	var issue Issue
+	if err := json.Unmarshal([]byte(`{"original_risk_rating":"high","client_defined_risk_rating":"Extreme"}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.OriginalRiskRating != RiskHigh {
+		t.Errorf("original_risk_rating = %q, want High", issue.OriginalRiskRating)
+	}
+	if issue.ClientDefinedRiskRating == nil || *issue.ClientDefinedRiskRating != "Extreme" {
+		t.Errorf("unrecognised ratings should be kept as written")
+	}
+}
@@ -0,0 +1,272 @@
+package PrismDataStructs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// schemaViolation is a value that does not match the Prism schema
+type schemaViolation struct {
+	Path    string
+	Message string
+}
+
+// schemaChecker evaluates the subset of JSON Schema used by prism.schema.json:
+// $ref, anyOf, type, enum, pattern, minLength, minimum, maximum, required,
+// properties and items
+type schemaChecker struct {
+	root map[string]interface{}
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+var (
+	embeddedSchemaOnce    sync.Once
+	embeddedSchemaChecker *schemaChecker
+	embeddedSchemaErr     error
+)
+
+// embeddedSchema returns a checker for the checked in prism.schema.json
+func embeddedSchema() (*schemaChecker, error) {
+	embeddedSchemaOnce.Do(func() {
+		var root map[string]interface{}
+		if embeddedSchemaErr = json.Unmarshal(SchemaJSON, &root); embeddedSchemaErr != nil {
+			embeddedSchemaErr = fmt.Errorf("prism.schema.json: %w", embeddedSchemaErr)
+			return
+		}
+		embeddedSchemaChecker = &schemaChecker{root: root, patterns: make(map[string]*regexp.Regexp)}
+	})
+	return embeddedSchemaChecker, embeddedSchemaErr
+}
+
+// checkDefinition validates value, as decoded by decodeGeneric, against one
+// of the definitions under $defs
+func (c *schemaChecker) checkDefinition(name string, value interface{}, path string) []schemaViolation {
+	var violations []schemaViolation
+	c.check(map[string]interface{}{"$ref": "#/$defs/" + name}, value, path, &violations)
+	return violations
+}
+
+func (c *schemaChecker) check(schema map[string]interface{}, value interface{}, path string, violations *[]schemaViolation) {
+	add := func(format string, args ...interface{}) {
+		*violations = append(*violations, schemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := c.resolve(ref)
+		if err != nil {
+			add("%v", err)
+			return
+		}
+		c.check(resolved, value, path, violations)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, option := range anyOf {
+			var optionViolations []schemaViolation
+			c.check(option.(map[string]interface{}), value, path, &optionViolations)
+			if len(optionViolations) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("value does not match any allowed form")
+			return
+		}
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		add("expected %s, found %s", describeTypes(types), jsonType(value))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		var allowed []string
+		for _, option := range enum {
+			if reflect.DeepEqual(option, normaliseNumber(value)) {
+				found = true
+				break
+			}
+			if option != nil && option != "" {
+				allowed = append(allowed, fmt.Sprint(option))
+			}
+		}
+		if !found {
+			add("%s is not one of %s", describeValue(value), strings.Join(allowed, ", "))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := c.compile(pattern)
+			if err != nil {
+				add("invalid schema pattern: %v", err)
+			} else if !re.MatchString(v) {
+				add("%q does not match the expected format", v)
+			}
+		}
+		if minLength, ok := schema["minLength"].(float64); ok && float64(utf8.RuneCountInString(v)) < minLength {
+			if minLength == 1 {
+				add("value must not be empty")
+			} else {
+				add("value must be at least %v characters", minLength)
+			}
+		}
+
+	case json.Number:
+		f, _ := v.Float64()
+		if minimum, ok := schema["minimum"].(float64); ok && f < minimum {
+			add("%s is below the minimum of %v", v, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && f > maximum {
+			add("%s is above the maximum of %v", v, maximum)
+		}
+
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, key := range required {
+				if _, present := v[key.(string)]; !present {
+					add("%s is required", key)
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if property, ok := properties[key].(map[string]interface{}); ok {
+					c.check(property, v[key], path+"."+key, violations)
+				}
+			}
+		}
+
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for index, item := range v {
+				c.check(items, item, fmt.Sprintf("%s[%d]", path, index), violations)
+			}
+		}
+	}
+}
+
+func (c *schemaChecker) resolve(ref string) (map[string]interface{}, error) {
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	defs, _ := c.root["$defs"].(map[string]interface{})
+	schema, ok := defs[name].(map[string]interface{})
+	if !ok || name == ref {
+		return nil, fmt.Errorf("unresolved schema reference %q", ref)
+	}
+	return schema, nil
+}
+
+func (c *schemaChecker) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if re, ok := c.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.patterns[pattern] = re
+	return re, nil
+}
+
+func matchesType(types interface{}, value interface{}) bool {
+	switch t := types.(type) {
+	case string:
+		return typeMatches(t, value)
+	case []interface{}:
+		for _, name := range t {
+			if typeMatches(name.(string), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeMatches(name string, value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return name == "null"
+	case bool:
+		return name == "boolean"
+	case string:
+		return name == "string"
+	case json.Number:
+		if name == "number" {
+			return true
+		}
+		f, err := v.Float64()
+		return name == "integer" && err == nil && f == math.Trunc(f)
+	case map[string]interface{}:
+		return name == "object"
+	case []interface{}:
+		return name == "array"
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return "unknown"
+}
+
+func describeTypes(types interface{}) string {
+	if list, ok := types.([]interface{}); ok {
+		names := make([]string, len(list))
+		for i, name := range list {
+			names[i] = fmt.Sprint(name)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func describeValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+// normaliseNumber turns json.Number into float64 so it compares equal to
+// the numbers in an enum decoded by json.Unmarshal
+func normaliseNumber(value interface{}) interface{} {
+	if n, ok := value.(json.Number); ok {
+		f, _ := n.Float64()
+		return f
+	}
+	return value
+}
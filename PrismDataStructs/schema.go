@@ -0,0 +1,203 @@
+package PrismDataStructs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//go:generate go run ./cmd/prism-schema -o prism.schema.json
+
+// SchemaJSON is the JSON Schema for Prism files, generated from the model
+// types by GenerateSchema and checked in as prism.schema.json
+//
+//go:embed prism.schema.json
+var SchemaJSON []byte
+
+const (
+	schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	schemaID      = "https://github.com/MantisSTS/PrismTools/blob/main/PrismDataStructs/prism.schema.json"
+)
+
+const (
+	datePattern = `^(\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?)?)?$`
+	// cvssPattern is the grammar of the vectors ParseCVSS reads: a CVSS v2
+	// vector (bare, in parentheses or after CVSS2#) or a CVSS:3.0, 3.1 or 4.0
+	// prefix, then name:value metrics in any order and case. Which metrics
+	// and values are valid is left to ParseCVSS.
+	cvssPattern = `^\s*(([Cc][Vv][Ss][Ss]2#|\()?[A-Za-z]+:[A-Za-z]+(/[A-Za-z]+:[A-Za-z]+)*\)?` +
+		`|[Cc][Vv][Ss][Ss]:(3\.[01]|4\.0)(/[A-Za-z]+:[A-Za-z]+)+)/?\s*$`
+	wholeNumberPattern = `^\s*(-?\d+(\.0+)?)?\s*$`
+)
+
+// schemaConstraints are the hand-written rules layered over the generated
+// schema, keyed by definition name and then JSON key
+var schemaConstraints = map[string]map[string]map[string]interface{}{
+	"Prism": {
+		"version": {"minimum": 0},
+	},
+	"Phase": {
+		"start_date": {"pattern": datePattern},
+		"end_date":   {"pattern": datePattern},
+	},
+	"Issue": {
+		"name":                       {"minLength": 1},
+		"finding":                    {"minLength": 1},
+		"summary":                    {"type": "string"},
+		"recommendation":             {"type": "string"},
+		"original_risk_rating":       {"enum": riskRatingEnum()},
+		"client_defined_risk_rating": {"enum": append(riskRatingEnum(), "", nil)},
+		"cvss_vector":                {"pattern": cvssPattern},
+		"confirmed_at":               {"pattern": datePattern},
+		"remediated_at":              {"pattern": datePattern},
+		"published_at":               {"pattern": datePattern},
+	},
+	"AffectedHost": {
+		"port": {"minimum": 1, "maximum": 65535},
+	},
+}
+
+// schemaRequired lists the keys that must be present in each definition
+var schemaRequired = map[string][]string{
+	"Prism": {"issues"},
+	"Issue": {"name", "finding", "original_risk_rating", "summary", "recommendation"},
+}
+
+// schemaDefinitions are the model types described under $defs
+var schemaDefinitions = []reflect.Type{
+	reflect.TypeOf(Phase{}),
+	reflect.TypeOf(Issue{}),
+	reflect.TypeOf(AffectedHost{}),
+}
+
+// GenerateSchema derives the JSON Schema for Prism files from the model
+// types: pointers and slices are nullable, slices are arrays, and the
+// tolerant number types accept integers or numeric strings. The hand-written
+// constraints are merged on top.
+func GenerateSchema() map[string]interface{} {
+	schema := objectSchema(reflect.TypeOf(Prism{}))
+	schema["$schema"] = schemaDialect
+	schema["$id"] = schemaID
+	schema["title"] = "Prism project export"
+
+	defs := make(map[string]interface{})
+	for _, t := range schemaDefinitions {
+		defs[t.Name()] = objectSchema(t)
+	}
+	schema["$defs"] = defs
+	return schema
+}
+
+// objectSchema describes a model struct, allowing the unknown keys that
+// Extra preserves
+func objectSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := typeSchema(field.Type)
+		for key, value := range schemaConstraints[t.Name()][name] {
+			property[key] = value
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": true,
+	}
+	if required := schemaRequired[t.Name()]; len(required) > 0 {
+		sorted := append([]string{}, required...)
+		sort.Strings(sorted)
+		schema["required"] = sorted
+	}
+	return schema
+}
+
+var tolerantNumberTypes = map[reflect.Type]bool{
+	reflect.TypeOf(NessusId{}): true,
+	reflect.TypeOf(Port{}):     true,
+	reflect.TypeOf(Id{}):       true,
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if tolerantNumberTypes[t] {
+		return map[string]interface{}{
+			"type":    []interface{}{"integer", "string", "null"},
+			"pattern": wholeNumberPattern,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(typeSchema(t.Elem()))
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  []interface{}{"array", "null"},
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+
+	// interface{} accepts anything
+	return map[string]interface{}{}
+}
+
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = []interface{}{t, "null"}
+	case []interface{}:
+		for _, name := range t {
+			if name == "null" {
+				return schema
+			}
+		}
+		schema["type"] = append(t, "null")
+	default:
+		if ref, ok := schema["$ref"]; ok {
+			return map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{"$ref": ref},
+					map[string]interface{}{"type": "null"},
+				},
+			}
+		}
+	}
+	return schema
+}
+
+// riskRatingEnum lists the Prism ratings for the schema
+func riskRatingEnum() []interface{} {
+	enum := make([]interface{}, 0, len(RiskRatings))
+	for _, rating := range RiskRatings {
+		enum = append(enum, string(rating))
+	}
+	return enum
+}
+
+// MarshalSchema returns GenerateSchema as indented JSON, the form checked in
+// as prism.schema.json
+func MarshalSchema() ([]byte, error) {
+	out, err := json.MarshalIndent(GenerateSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,82 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	schema, err := MarshalSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(schema, SchemaJSON) {
+		t.Error("prism.schema.json is out of date with the model types, run go generate")
+	}
+}
+
+func TestSchemaChecksTolerantPorts(t *testing.T) {
+	checker, err := embeddedSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for input, want := range map[string]int{
+		`{"ip":"10.0.0.1","port":443}`:     0,
+		`{"ip":"10.0.0.1","port":"443"}`:   0,
+		`{"ip":"10.0.0.1","port":null}`:    0,
+		`{"ip":"10.0.0.1","port":70000}`:   1,
+		`{"ip":"10.0.0.1","port":"https"}`: 1,
+		`{"ip":10}`:                        1,
+	} {
+		value, err := decodeGeneric([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := checker.checkDefinition("AffectedHost", value, "$"); len(got) != want {
+			t.Errorf("%s: %d violations %+v, want %d", input, len(got), got, want)
+		}
+	}
+}
+
+func TestSchemaAcceptsParsableCVSS(t *testing.T) {
+	prism, err := LoadFile(filepath.Join("testdata", "nessus-id-number.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vector := range []string{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"cvss:3.0/av:n/ac:l/pr:n/ui:n/s:c/c:h/i:h/a:h",
+		"CVSS:3.1/S:U/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P/RL:O/RC:C/MAV:L/",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/U:Amber",
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"av:n/ac:l/au:n/c:p/i:p/a:p",
+		"CVSS2#AV:N/AC:L/Au:N/C:P/I:P/A:P/E:POC/RL:OF/RC:C",
+		"(AV:N/AC:M/Au:N/C:N/I:P/A:N)",
+	} {
+		if _, err := ParseCVSS(vector); err != nil {
+			t.Fatalf("%s: %v", vector, err)
+		}
+
+		issue := prism.Issues[0]
+		issue.CvssVector = &vector
+		for _, problem := range Validate(&Prism{Issues: []Issue{issue}}).Problems {
+			if problem.Severity == SeverityError {
+				t.Errorf("%s: %s: %s", vector, problem.Path, problem.Message)
+			}
+		}
+	}
+
+	for _, vector := range []string{"CVSS:3.1/AV:X", "CVSS:3.1/AV:N", "high", "CVSS:5.0/AV:N"} {
+		issue := prism.Issues[0]
+		issue.CvssVector = &vector
+		report := Validate(&Prism{Issues: []Issue{issue}})
+		if report.Errors != 1 {
+			t.Errorf("%s: %d errors %+v, want 1", vector, report.Errors, report.Problems)
+		}
+	}
+}
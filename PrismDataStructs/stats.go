@@ -0,0 +1,171 @@
+package PrismDataStructs
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Count is one row of a breakdown
+type Count struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// StatsReport summarises a set of issues. Each breakdown counts issues, so
+// an issue affecting a host on three ports counts once for that host.
+type StatsReport struct {
+	Issues           int `json:"issues"`
+	AffectedHosts    int `json:"affected_hosts"`
+	UniqueHosts      int `json:"unique_hosts"`
+	ExploitAvailable int `json:"exploit_available"`
+
+	ByRating  []Count `json:"by_rating"`
+	ByHost    []Count `json:"by_host"`
+	ByPort    []Count `json:"by_port"`
+	ByService []Count `json:"by_service"`
+	ByCve     []Count `json:"by_cve"`
+
+	// TopIssues ranks issues by the number of hosts they affect
+	TopIssues []Count `json:"top_issues"`
+
+	// CVSS counts issues by the severity band of their base score, with
+	// Unscored for issues without a vector, with a v4.0 vector or with a
+	// vector that does not parse
+	CVSS []Count `json:"cvss"`
+}
+
+// Stats accumulates a StatsReport one issue at a time, so a file can be
+// streamed through it
+type Stats struct {
+	issues           int
+	affectedHosts    int
+	exploitAvailable int
+
+	byRating  map[string]int
+	byHost    map[string]int
+	byPort    map[string]int
+	byService map[string]int
+	byCve     map[string]int
+	byCVSS    map[string]int
+	topIssues []Count
+}
+
+const cvssUnscored = "Unscored"
+
+// NewStats returns an empty Stats
+func NewStats() *Stats {
+	return &Stats{
+		byRating:  make(map[string]int),
+		byHost:    make(map[string]int),
+		byPort:    make(map[string]int),
+		byService: make(map[string]int),
+		byCve:     make(map[string]int),
+		byCVSS:    make(map[string]int),
+	}
+}
+
+// Add counts one issue
+func (s *Stats) Add(issue *Issue) {
+	s.issues++
+	s.byRating[issue.OriginalRiskRating.String()]++
+	if issue.ExploitAvailable != nil && *issue.ExploitAvailable {
+		s.exploitAvailable++
+	}
+
+	hosts := NewHostSet(issue.AffectedHosts...)
+	s.affectedHosts += hosts.Len()
+	s.topIssues = append(s.topIssues, Count{issue.Name, hosts.Len()})
+
+	addresses := make(map[string]bool)
+	ports := make(map[string]bool)
+	services := make(map[string]bool)
+	for _, host := range hosts.Hosts() {
+		key := host.Key()
+		addresses[key.Address()] = true
+		if key.Port != 0 {
+			ports[strconv.Itoa(key.Port)+"/"+key.Protocol] = true
+		}
+		if host.Service != nil && *host.Service != "" {
+			services[*host.Service] = true
+		}
+	}
+	for address := range addresses {
+		s.byHost[address]++
+	}
+	for port := range ports {
+		s.byPort[port]++
+	}
+	for service := range services {
+		s.byService[service]++
+	}
+
+	if issue.Cves != nil {
+		for _, cve := range uniqueStrings(nil, *issue.Cves, normaliseCve) {
+			s.byCve[cve]++
+		}
+	}
+
+	band := cvssUnscored
+	if cvss, err := issue.CVSS(); err == nil && cvss.Scored {
+		band = cvss.Severity.CVSSSeverity()
+	}
+	s.byCVSS[band]++
+}
+
+// Report returns the statistics so far. When top is above zero the host and
+// issue rankings are cut to that many entries.
+func (s *Stats) Report(top int) *StatsReport {
+	report := &StatsReport{
+		Issues:           s.issues,
+		AffectedHosts:    s.affectedHosts,
+		UniqueHosts:      len(s.byHost),
+		ExploitAvailable: s.exploitAvailable,
+		ByHost:           limitCounts(sortedCounts(s.byHost), top),
+		ByPort:           sortedCounts(s.byPort),
+		ByService:        sortedCounts(s.byService),
+		ByCve:            sortedCounts(s.byCve),
+	}
+
+	// Ratings and CVSS bands keep their severity order, including empty ones
+	for _, rating := range RiskRatings {
+		report.ByRating = append(report.ByRating, Count{rating.String(), s.byRating[rating.String()]})
+		report.CVSS = append(report.CVSS, Count{rating.CVSSSeverity(), s.byCVSS[rating.CVSSSeverity()]})
+	}
+	report.CVSS = append(report.CVSS, Count{cvssUnscored, s.byCVSS[cvssUnscored]})
+	for _, count := range sortedCounts(s.byRating) {
+		if !RiskRating(count.Key).Valid() {
+			report.ByRating = append(report.ByRating, count)
+		}
+	}
+
+	topIssues := append([]Count(nil), s.topIssues...)
+	sortCounts(topIssues)
+	report.TopIssues = limitCounts(topIssues, top)
+	return report
+}
+
+func sortedCounts(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for key, count := range m {
+		counts = append(counts, Count{key, count})
+	}
+	sortCounts(counts)
+	return counts
+}
+
+// sortCounts orders by count, highest first, then by key
+func sortCounts(counts []Count) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+}
+
+func limitCounts(counts []Count, top int) []Count {
+	if top > 0 && len(counts) > top {
+		return counts[:top]
+	}
+	return counts
+}
@@ -0,0 +1,61 @@
+package PrismDataStructs
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	vector := "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	cves := []string{"CVE-2021-1", "cve-2021-1"}
+	exploit := true
+	www := "www"
+	issues := []Issue{
+		{Name: "A", OriginalRiskRating: RiskCritical, CvssVector: &vector, Cves: &cves, ExploitAvailable: &exploit, AffectedHosts: []AffectedHost{
+			{Ip: "10.0.0.1", Port: NewPort(443), Service: &www},
+			{Ip: "10.0.0.1", Port: NewPort(8443), Service: &www},
+			{Ip: "10.0.0.2", Port: NewPort(443)},
+		}},
+		{Name: "B", OriginalRiskRating: RiskLow, AffectedHosts: []AffectedHost{{Ip: "10.0.0.1", Port: NewPort(443)}}},
+		{Name: "C", OriginalRiskRating: "Extreme"},
+	}
+
+	stats := NewStats()
+	for i := range issues {
+		stats.Add(&issues[i])
+	}
+	report := stats.Report(1)
+
+	if report.Issues != 3 || report.AffectedHosts != 4 || report.UniqueHosts != 2 || report.ExploitAvailable != 1 {
+		t.Errorf("totals = %d issues, %d affected, %d unique, %d exploitable", report.Issues, report.AffectedHosts, report.UniqueHosts, report.ExploitAvailable)
+	}
+	if len(report.ByHost) != 1 || report.ByHost[0] != (Count{"10.0.0.1", 2}) {
+		t.Errorf("by host = %v", report.ByHost)
+	}
+	if len(report.TopIssues) != 1 || report.TopIssues[0] != (Count{"A", 3}) {
+		t.Errorf("top issues = %v", report.TopIssues)
+	}
+	if report.ByPort[0] != (Count{"443/tcp", 2}) || report.ByService[0] != (Count{"www", 1}) {
+		t.Errorf("by port = %v, by service = %v", report.ByPort, report.ByService)
+	}
+	if len(report.ByCve) != 1 || report.ByCve[0] != (Count{"CVE-2021-1", 1}) {
+		t.Errorf("by cve = %v", report.ByCve)
+	}
+	if len(report.ByRating) != 6 || report.ByRating[0] != (Count{"Critical", 1}) || report.ByRating[5] != (Count{"Extreme", 1}) {
+		t.Errorf("by rating = %v", report.ByRating)
+	}
+	if report.CVSS[0] != (Count{"Critical", 1}) || report.CVSS[5] != (Count{"Unscored", 2}) {
+		t.Errorf("cvss = %v", report.CVSS)
+	}
+}
+
+func TestStatsCVSSv2HasNoCriticalBand(t *testing.T) {
+	v2 := "AV:N/AC:L/Au:N/C:C/I:C/A:C"
+	v3 := "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"
+
+	stats := NewStats()
+	stats.Add(&Issue{Name: "v2", OriginalRiskRating: RiskHigh, CvssVector: &v2})
+	stats.Add(&Issue{Name: "v3", OriginalRiskRating: RiskCritical, CvssVector: &v3})
+	report := stats.Report(0)
+
+	if report.CVSS[0] != (Count{"Critical", 1}) || report.CVSS[1] != (Count{"High", 1}) {
+		t.Errorf("cvss = %v, want the 10.0 v2 vector counted as High", report.CVSS)
+	}
+}
@@ -0,0 +1,441 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Reader reads a Prism file one issue at a time, so memory use is bounded by
+// the largest single issue rather than by the size of the file
+type Reader struct {
+	dec *json.Decoder
+
+	// header collects every top level key other than "issues"
+	header map[string]json.RawMessage
+	prism  *Prism
+
+	version       int64
+	versionKnown  bool
+	path          []Migration
+	inIssues      bool
+	issuesStarted bool
+	done          bool
+	index         int
+}
+
+// NewReader returns a Reader for the Prism file in r
+func NewReader(r io.Reader) (*Reader, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("decoding prism file: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("decoding prism file: expected a JSON object")
+	}
+
+	return &Reader{
+		dec:    dec,
+		header: make(map[string]json.RawMessage),
+	}, nil
+}
+
+// Next returns the next issue, or io.EOF once the whole file has been read
+func (r *Reader) Next() (*Issue, error) {
+	for !r.done {
+		if r.inIssues {
+			if r.dec.More() {
+				return r.nextIssue()
+			}
+			if _, err := r.dec.Token(); err != nil {
+				return nil, r.wrap(err)
+			}
+			r.inIssues = false
+			continue
+		}
+
+		if !r.dec.More() {
+			if _, err := r.dec.Token(); err != nil {
+				return nil, r.wrap(err)
+			}
+			if err := r.finish(); err != nil {
+				return nil, err
+			}
+			r.done = true
+			break
+		}
+
+		tok, err := r.dec.Token()
+		if err != nil {
+			return nil, r.wrap(err)
+		}
+		key, _ := tok.(string)
+
+		if key == "issues" {
+			if err = r.startIssues(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err = r.dec.Decode(&raw); err != nil {
+			return nil, r.wrap(err)
+		}
+		r.header[key] = raw
+
+		if key == "version" {
+			version, err := parseVersion(raw)
+			if err != nil {
+				return nil, err
+			}
+			if err = r.setVersion(version); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, io.EOF
+}
+
+// Header returns the top level fields of the file (everything but the
+// issues), upgraded to CurrentVersion. It is nil until Next returns io.EOF.
+func (r *Reader) Header() *Prism {
+	return r.prism
+}
+
+func (r *Reader) startIssues() error {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return r.wrap(err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("decoding prism file: issues must be an array")
+	}
+
+	r.inIssues = true
+	r.issuesStarted = true
+	return nil
+}
+
+func (r *Reader) nextIssue() (*Issue, error) {
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		return nil, r.wrap(err)
+	}
+
+	// The issue has been read whether or not it decodes, so a bad issue
+	// does not stop the rest of the file being read
+	index := r.index
+	r.index++
+
+	migrated, err := migrateIssue(raw, r.path)
+	if err != nil {
+		return nil, &IssueDecodeError{Index: index, Name: issueName(raw), Path: fmt.Sprintf("issues[%d]", index), Err: err}
+	}
+
+	var issue Issue
+	if err = json.Unmarshal(migrated, &issue); err != nil {
+		return nil, &IssueDecodeError{Index: index, Name: issueName(raw), Path: fmt.Sprintf("issues[%d]", index) + issueErrorPath(migrated), Err: err}
+	}
+	return &issue, nil
+}
+
+// IssueDecodeError is an issue that could not be decoded. The Reader has
+// read past it, so Next can be called again for the issues after it.
+type IssueDecodeError struct {
+	Index int
+	Name  string
+
+	// Path is the JSON path of the value that failed to decode, such as
+	// issues[0].affected_hosts[1].port
+	Path string
+	Err  error
+}
+
+func (e *IssueDecodeError) Error() string {
+	return fmt.Sprintf("decoding prism file: %s: %v", e.Path, e.Err)
+}
+
+func (e *IssueDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// issueName is the name of an issue that failed to decode, if it has one
+func issueName(raw json.RawMessage) string {
+	var named struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(raw, &named)
+	return named.Name
+}
+
+// issueErrorPath finds the field of an issue that fails to decode, returning
+// it as a path suffix such as .affected_hosts[0].port, or "" when no single
+// field is to blame
+func issueErrorPath(raw json.RawMessage) string {
+	return fieldErrorPath(raw, func(data []byte) error {
+		var issue Issue
+		return json.Unmarshal(data, &issue)
+	}, map[string]func(json.RawMessage) (string, bool){
+		"affected_hosts": affectedHostsErrorPath,
+	})
+}
+
+// affectedHostsErrorPath finds the host that fails to decode, returning its
+// index and field such as [1].port
+func affectedHostsErrorPath(value json.RawMessage) (string, bool) {
+	var hosts []json.RawMessage
+	if json.Unmarshal(value, &hosts) != nil {
+		return "", true
+	}
+	for i, raw := range hosts {
+		var host AffectedHost
+		if json.Unmarshal(raw, &host) != nil {
+			return fmt.Sprintf("[%d]", i) + fieldErrorPath(raw, func(data []byte) error {
+				return json.Unmarshal(data, &AffectedHost{})
+			}, nil), true
+		}
+	}
+	return "", false
+}
+
+// fieldErrorPath decodes each field of the object raw on its own to find the
+// one that fails. Fields listed in nested are searched by their own function,
+// keyed by lower case name as known fields are matched case-insensitively.
+func fieldErrorPath(raw json.RawMessage, decode func(data []byte) error, nested map[string]func(json.RawMessage) (string, bool)) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if search, ok := nested[strings.ToLower(key)]; ok {
+			if suffix, failed := search(fields[key]); failed {
+				return "." + key + suffix
+			}
+			continue
+		}
+
+		single, err := json.Marshal(map[string]json.RawMessage{key: fields[key]})
+		if err == nil && decode(single) != nil {
+			return "." + key
+		}
+	}
+	return ""
+}
+
+// setVersion records the schema version and the migrations it needs. Issues
+// already returned were read as-is, which is only safe when the migrations
+// do not touch issues.
+func (r *Reader) setVersion(version int64) error {
+	path, err := migrationPath(version, CurrentVersion)
+	if err != nil {
+		return err
+	}
+	if r.issuesStarted && hasIssueHooks(path) {
+		return fmt.Errorf("version %d is declared after the issues and needs them migrated, load the file with LoadFile instead", version)
+	}
+
+	r.version = version
+	r.versionKnown = true
+	r.path = path
+	return nil
+}
+
+func (r *Reader) finish() error {
+	if !r.versionKnown {
+		if err := r.setVersion(UnversionedVersion); err != nil {
+			return err
+		}
+	}
+
+	header, err := migrateHeader(r.header, r.path)
+	if err != nil {
+		return err
+	}
+	data, err := marshalUnescaped(header)
+	if err != nil {
+		return err
+	}
+
+	var prism Prism
+	if err = json.Unmarshal(data, &prism); err != nil {
+		return fmt.Errorf("decoding prism file: %w", err)
+	}
+	r.prism = &prism
+	return nil
+}
+
+func (r *Reader) wrap(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("decoding prism file: %w", err)
+}
+
+var errWriterClosed = errors.New("prism writer is closed")
+
+// Writer writes a Prism file one issue at a time. The issues are written
+// first and the rest of the file when the Writer is closed, which matches
+// the layout Save produces.
+type Writer struct {
+	w     io.Writer
+	opts  SaveOptions
+	path  []Migration
+	count int
+	err   error
+}
+
+// NewWriter starts a Prism file on w
+func NewWriter(w io.Writer, opts SaveOptions) (*Writer, error) {
+	writer := &Writer{w: w, opts: opts}
+	if opts.TargetVersion != nil {
+		path, err := migrationPath(CurrentVersion, *opts.TargetVersion)
+		if err != nil {
+			return nil, err
+		}
+		writer.path = path
+	}
+
+	open := `{"issues":[`
+	if opts.Indent != "" {
+		open = "{\n" + opts.Indent + `"issues": [`
+	}
+	if _, err := io.WriteString(w, open); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WriteIssue appends an issue to the file
+func (w *Writer) WriteIssue(issue *Issue) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.opts.NumericForm != FormPreserve {
+		issue.SetNumericForm(w.opts.NumericForm)
+	}
+
+	raw, err := marshalUnescaped(issue)
+	if err == nil {
+		raw, err = migrateIssue(raw, w.path)
+	}
+	if err != nil {
+		return fmt.Errorf("issues[%d]: %w", w.count, err)
+	}
+
+	var buf bytes.Buffer
+	if w.count > 0 {
+		buf.WriteByte(',')
+	}
+	if w.opts.Indent != "" {
+		prefix := strings.Repeat(w.opts.Indent, 2)
+		buf.WriteString("\n" + prefix)
+		w.err = json.Indent(&buf, w.escape(raw), prefix, w.opts.Indent)
+	} else {
+		buf.Write(w.escape(raw))
+	}
+
+	if w.err == nil {
+		_, w.err = w.w.Write(buf.Bytes())
+	}
+	w.count++
+	return w.err
+}
+
+// Close finishes the issues array and writes the remaining top level fields
+// from header, whose Issues are ignored
+func (w *Writer) Close(header *Prism) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	tail, err := w.tail(header)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if w.count > 0 && w.opts.Indent != "" {
+		buf.WriteString("\n" + w.opts.Indent)
+	}
+	buf.WriteByte(']')
+
+	if len(tail) > 2 {
+		buf.WriteByte(',')
+		tail = w.escape(tail)
+		if w.opts.Indent != "" {
+			var indented bytes.Buffer
+			if err = json.Indent(&indented, tail, "", w.opts.Indent); err != nil {
+				return err
+			}
+			tail = indented.Bytes()
+		}
+		// Drop the opening brace, the issues array already opened the object
+		buf.Write(tail[1:])
+	} else {
+		if w.opts.Indent != "" {
+			buf.WriteString("\n")
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('\n')
+
+	if _, err = w.w.Write(buf.Bytes()); err != nil {
+		w.err = err
+		return err
+	}
+	w.err = errWriterClosed
+	return nil
+}
+
+// tail returns the header fields as a compact JSON object without "issues"
+func (w *Writer) tail(header *Prism) ([]byte, error) {
+	fields := prismFields{}
+	if header != nil {
+		fields = prismFields(*header)
+	}
+	fields.Issues = nil
+
+	raw, err := encodeWithExtra(fields, fields.Extra)
+	if err != nil {
+		return nil, err
+	}
+	if w.path == nil {
+		// encodeWithExtra always writes "issues" first
+		return append([]byte("{"), bytes.TrimPrefix(raw, []byte(`{"issues":null,`))...), nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err = json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "issues")
+	if doc, err = migrateHeader(doc, w.path); err != nil {
+		return nil, err
+	}
+	return marshalUnescaped(doc)
+}
+
+func (w *Writer) escape(raw []byte) []byte {
+	if !w.opts.EscapeHTML {
+		return raw
+	}
+	var buf bytes.Buffer
+	json.HTMLEscape(&buf, raw)
+	return buf.Bytes()
+}
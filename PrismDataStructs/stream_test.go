@@ -0,0 +1,126 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStreamMatchesSave(t *testing.T) {
+	files := []string{"nessus-id-number.json", "nessus-id-string.json", "nessus-id-empty.json", "unknown-fields.json"}
+	for _, file := range files {
+		for _, opts := range []SaveOptions{DefaultSaveOptions, {}} {
+			data, err := os.ReadFile(filepath.Join("testdata", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prism, err := Load(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want bytes.Buffer
+			if err = prism.Save(&want, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			writer, err := NewWriter(&got, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for {
+				issue, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", file, err)
+				}
+				if err = writer.WriteIssue(issue); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err = writer.Close(reader.Header()); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != want.String() {
+				t.Errorf("%s (indent %q): streamed output differs from Save\ngot:\n%s\nwant:\n%s", file, opts.Indent, got.String(), want.String())
+			}
+		}
+	}
+}
+
+func TestStreamVersionHandling(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(`{"issues":[{"name":"a"},{"name":"b"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for {
+		_, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 2 || reader.Header().Version != CurrentVersion {
+		t.Errorf("read %d issues at version %d, want 2 at %d", count, reader.Header().Version, CurrentVersion)
+	}
+
+	reader, err = NewReader(strings.NewReader(`{"version":7,"issues":[{"name":"a"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reader.Next(); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("err = %v, want ErrUnknownVersion", err)
+	}
+}
+
+func TestStreamSkipsIssuesThatFailToDecode(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(`{"issues":[
+		{"name":"a","affected_hosts":[{"ip":"10.0.0.1","port":"https"}]},
+		{"name":"b","cvss_vector":5},
+		{"name":"c"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths, names []string
+	for {
+		issue, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var decodeErr *IssueDecodeError
+		if errors.As(err, &decodeErr) {
+			paths = append(paths, decodeErr.Path)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, issue.Name)
+	}
+
+	want := []string{"issues[0].affected_hosts[0].port", "issues[1].cvss_vector"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("error paths = %v, want %v", paths, want)
+	}
+	if len(names) != 1 || names[0] != "c" {
+		t.Errorf("decoded %v, want the issue after the bad ones", names)
+	}
+}
@@ -0,0 +1,202 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumericForm selects how NessusId, Port and Id values are written out
+type NumericForm int
+
+const (
+	// FormPreserve writes each value back in the form it was read
+	FormPreserve NumericForm = iota
+
+	// FormNumber writes every set value as a JSON number and every unset value as null
+	FormNumber
+
+	// FormString writes every set value as a JSON string and every unset value as null
+	FormString
+)
+
+// numberKind records which JSON form a tolerant number was decoded from
+type numberKind uint8
+
+const (
+	kindNull numberKind = iota
+	kindEmpty
+	kindNumber
+	kindString
+)
+
+// tolerantNumber is an optional integer that Prism exports write either as a
+// number, a numeric string, an empty string or null
+type tolerantNumber struct {
+	value int64
+	kind  numberKind
+	form  NumericForm
+}
+
+func newTolerantNumber(v int64) tolerantNumber {
+	return tolerantNumber{value: v, kind: kindNumber}
+}
+
+// Int64 returns the value and whether it is set
+func (n tolerantNumber) Int64() (int64, bool) {
+	return n.value, n.IsSet()
+}
+
+// IsSet reports whether the field holds a value, as opposed to null or ""
+func (n tolerantNumber) IsSet() bool {
+	return n.kind == kindNumber || n.kind == kindString
+}
+
+// String returns the decimal value, or an empty string when unset
+func (n tolerantNumber) String() string {
+	if !n.IsSet() {
+		return ""
+	}
+	return strconv.FormatInt(n.value, 10)
+}
+
+// SetForm overrides how this value is written, see NumericForm
+func (n *tolerantNumber) SetForm(form NumericForm) {
+	n.form = form
+}
+
+func (n tolerantNumber) MarshalJSON() ([]byte, error) {
+	switch n.form {
+	case FormNumber:
+		if !n.IsSet() {
+			return []byte("null"), nil
+		}
+		return []byte(n.String()), nil
+	case FormString:
+		if !n.IsSet() {
+			return []byte("null"), nil
+		}
+		return json.Marshal(n.String())
+	}
+
+	switch n.kind {
+	case kindEmpty:
+		return []byte(`""`), nil
+	case kindNumber:
+		return []byte(n.String()), nil
+	case kindString:
+		return json.Marshal(n.String())
+	}
+	return []byte("null"), nil
+}
+
+func (n *tolerantNumber) decode(field string, data []byte) error {
+	form := n.form
+	*n = tolerantNumber{form: form}
+
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	kind := kindNumber
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			n.kind = kindEmpty
+			return nil
+		}
+		kind = kindString
+	}
+
+	value, err := parseWholeNumber(text)
+	if err != nil {
+		return fmt.Errorf("%s: %s is not a whole number", field, data)
+	}
+	n.value = value
+	n.kind = kind
+	return nil
+}
+
+// parseWholeNumber accepts integers as well as floats with no fractional part
+// such as 443.0, which some exporters emit
+func parseWholeNumber(text string) (int64, error) {
+	if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return value, nil
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, err
+	}
+	// math.MaxInt64 converts up to 2^63, which int64 cannot hold
+	if f != math.Trunc(f) || math.IsInf(f, 0) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, strconv.ErrSyntax
+	}
+	return int64(f), nil
+}
+
+// NessusId is the Tenable plugin ID of an issue
+type NessusId struct{ tolerantNumber }
+
+// NewNessusId returns a NessusId holding v
+func NewNessusId(v int64) NessusId {
+	return NessusId{newTolerantNumber(v)}
+}
+
+func (n *NessusId) UnmarshalJSON(data []byte) error {
+	return n.decode("nessus_id", data)
+}
+
+// Port is the port number of an affected host
+type Port struct{ tolerantNumber }
+
+// NewPort returns a Port holding v
+func NewPort(v int) Port {
+	return Port{newTolerantNumber(int64(v))}
+}
+
+// Int returns the port number, or 0 when unset
+func (p Port) Int() int {
+	return int(p.value)
+}
+
+func (p *Port) UnmarshalJSON(data []byte) error {
+	return p.decode("port", data)
+}
+
+// Id is the Prism database ID of an issue
+type Id struct{ tolerantNumber }
+
+// NewId returns an Id holding v
+func NewId(v int64) Id {
+	return Id{newTolerantNumber(v)}
+}
+
+func (i *Id) UnmarshalJSON(data []byte) error {
+	return i.decode("id", data)
+}
+
+// SetNumericForm applies form to every NessusId, Port and Id in the project
+func (p *Prism) SetNumericForm(form NumericForm) {
+	for issueIndex := range p.Issues {
+		p.Issues[issueIndex].SetNumericForm(form)
+	}
+}
+
+// SetNumericForm applies form to the issue's NessusId and Id and to the
+// Port of each affected host
+func (i *Issue) SetNumericForm(form NumericForm) {
+	i.Id.SetForm(form)
+	i.NessusId.SetForm(form)
+	for hostIndex := range i.AffectedHosts {
+		i.AffectedHosts[hostIndex].Port.SetForm(form)
+	}
+}
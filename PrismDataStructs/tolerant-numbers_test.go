@@ -0,0 +1,154 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTolerantNumbers(t *testing.T) {
+	tests := []struct {
+		file     string
+		nessusId string
+		id       string
+		ports    []string
+	}{
+		{"nessus-id-number.json", "42873", "1042", []string{"443"}},
+		{"nessus-id-string.json", "10815", "1043", []string{"8443"}},
+		{"nessus-id-empty.json", "", "", []string{"", ""}},
+	}
+
+	for _, test := range tests {
+		prism, err := LoadFile(filepath.Join("testdata", test.file))
+		if err != nil {
+			t.Fatalf("%s: %v", test.file, err)
+		}
+
+		issue := prism.Issues[0]
+		if got := issue.NessusId.String(); got != test.nessusId {
+			t.Errorf("%s: nessus_id = %q, want %q", test.file, got, test.nessusId)
+		}
+		if got := issue.Id.String(); got != test.id {
+			t.Errorf("%s: id = %q, want %q", test.file, got, test.id)
+		}
+		for hostIndex, want := range test.ports {
+			if got := issue.AffectedHosts[hostIndex].Port.String(); got != want {
+				t.Errorf("%s: host %d port = %q, want %q", test.file, hostIndex, got, want)
+			}
+		}
+	}
+}
+
+func TestRoundTripPreservesForm(t *testing.T) {
+	for _, file := range []string{"nessus-id-number.json", "nessus-id-string.json", "nessus-id-empty.json"} {
+		want, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		prism, err := Load(bytes.NewReader(want))
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		var got bytes.Buffer
+		if err = prism.Save(&got, SaveOptions{Indent: "  "}); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if got.String() != string(want) {
+			t.Errorf("%s: round trip changed the file:\n%s", file, got.String())
+		}
+	}
+}
+
+func TestSaveCanonicalForm(t *testing.T) {
+	tests := []struct {
+		form NumericForm
+		want []string
+	}{
+		{FormNumber, []string{`"nessus_id":10815`, `"id":1043`, `"port":8443`}},
+		{FormString, []string{`"nessus_id":"10815"`, `"id":"1043"`, `"port":"8443"`}},
+	}
+
+	for _, test := range tests {
+		prism, err := LoadFile(filepath.Join("testdata", "nessus-id-string.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		if err = prism.Save(&out, SaveOptions{NumericForm: test.form}); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("form %d: output missing %s", test.form, want)
+			}
+		}
+	}
+
+	prism, err := LoadFile(filepath.Join("testdata", "nessus-id-empty.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err = prism.Save(&out, SaveOptions{NumericForm: FormString}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"nessus_id":null`, `"port":null,"protocol"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("empty values should be written as null, output missing %s", want)
+		}
+	}
+	if strings.Contains(out.String(), `"port":""`) {
+		t.Errorf("empty port should be written as null in canonical form")
+	}
+}
+
+func TestTolerantNumberDecoding(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		set   bool
+	}{
+		{`19506`, "19506", true},
+		{`"19506"`, "19506", true},
+		{`" 19506 "`, "19506", true},
+		{`443.0`, "443", true},
+		{`""`, "", false},
+		{`null`, "", false},
+	}
+
+	for _, test := range tests {
+		var id NessusId
+		if err := json.Unmarshal([]byte(test.input), &id); err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		if id.String() != test.want || id.IsSet() != test.set {
+			t.Errorf("%s: got %q (set %v), want %q (set %v)", test.input, id.String(), id.IsSet(), test.want, test.set)
+		}
+	}
+
+	// The largest and smallest floats an int64 holds, either side of 2^63
+	for input, want := range map[string]int64{
+		`9223372036854774784.0`:  9223372036854774784,
+		`-9223372036854775808.0`: math.MinInt64,
+	} {
+		if value, err := parseWholeNumber(input); err != nil || value != want {
+			t.Errorf("parseWholeNumber(%s) = %d, %v, want %d", input, value, err, want)
+		}
+	}
+
+	for _, input := range []string{`"abc"`, `12.5`, `true`, `[1]`, `9223372036854775808.0`, `1e19`, `-1e19`} {
+		var port Port
+		if err := json.Unmarshal([]byte(input), &port); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The xxxFields types share the layout of the model structs but not their
+// methods, so they can be passed to encoding/json without recursing
+type (
+	prismFields        Prism
+	phaseFields        Phase
+	issueFields        Issue
+	affectedHostFields AffectedHost
+)
+
+// knownFieldCache maps a struct type to the set of JSON keys it declares
+var knownFieldCache sync.Map
+
+// knownFields returns the JSON keys declared by the struct type t, in lower
+// case, as encoding/json matches keys to fields whatever their case
+func knownFields(t reflect.Type) map[string]bool {
+	if cached, ok := knownFieldCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			name, _, _ = strings.Cut(tag, ",")
+		}
+		if name == "-" {
+			continue
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	knownFieldCache.Store(t, known)
+	return known
+}
+
+// decodeWithExtra decodes data into fields (a pointer to one of the xxxFields
+// types) and returns every key the struct does not declare
+func decodeWithExtra(data []byte, fields interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, fields); err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	known := knownFields(reflect.TypeOf(fields).Elem())
+	var extra map[string]json.RawMessage
+	for key, value := range all {
+		if known[strings.ToLower(key)] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[key] = value
+	}
+	return extra, nil
+}
+
+// encodeWithExtra encodes fields and appends the extra keys, sorted, after the
+// declared ones. HTML is left unescaped here; the outer encoder applies the
+// caller's EscapeHTML setting when it compacts the result.
+func encodeWithExtra(fields interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	out, err := marshalUnescaped(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(extra) == 0 {
+		return out, nil
+	}
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Drop the closing brace, then add each extra key
+	out = out[:len(out)-1]
+	for _, key := range keys {
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value := extra[key]
+		if len(value) == 0 {
+			value = json.RawMessage("null")
+		}
+		out = append(out, name...)
+		out = append(out, ':')
+		out = append(out, value...)
+	}
+	return append(out, '}'), nil
+}
+
+// copyExtra adds the keys of src missing from dst, allocating dst if needed
+func copyExtra(dst, src map[string]json.RawMessage) map[string]json.RawMessage {
+	for key, value := range src {
+		if dst == nil {
+			dst = make(map[string]json.RawMessage, len(src))
+		}
+		if _, ok := dst[key]; !ok {
+			dst[key] = value
+		}
+	}
+	return dst
+}
+
+// marshalUnescaped is json.Marshal without HTML escaping
+func marshalUnescaped(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (p *Prism) UnmarshalJSON(data []byte) error {
+	extra, err := decodeWithExtra(data, (*prismFields)(p))
+	p.Extra = extra
+	return err
+}
+
+func (p Prism) MarshalJSON() ([]byte, error) {
+	return encodeWithExtra(prismFields(p), p.Extra)
+}
+
+func (p *Phase) UnmarshalJSON(data []byte) error {
+	extra, err := decodeWithExtra(data, (*phaseFields)(p))
+	p.Extra = extra
+	return err
+}
+
+func (p Phase) MarshalJSON() ([]byte, error) {
+	return encodeWithExtra(phaseFields(p), p.Extra)
+}
+
+func (i *Issue) UnmarshalJSON(data []byte) error {
+	extra, err := decodeWithExtra(data, (*issueFields)(i))
+	i.Extra = extra
+	return err
+}
+
+func (i Issue) MarshalJSON() ([]byte, error) {
+	return encodeWithExtra(issueFields(i), i.Extra)
+}
+
+func (h *AffectedHost) UnmarshalJSON(data []byte) error {
+	extra, err := decodeWithExtra(data, (*affectedHostFields)(h))
+	h.Extra = extra
+	return err
+}
+
+func (h AffectedHost) MarshalJSON() ([]byte, error) {
+	return encodeWithExtra(affectedHostFields(h), h.Extra)
+}
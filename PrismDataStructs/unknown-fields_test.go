@@ -0,0 +1,73 @@
+package PrismDataStructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoundTripKeepsUnknownFields(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("testdata", "unknown-fields.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prism, err := Load(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		level string
+		extra map[string]json.RawMessage
+		key   string
+		want  string
+	}{
+		{"prism", prism.Extra, "project_reference", `"PRJ-1234"`},
+		{"phase", prism.Phase.Extra, "client_contact", "{\n      \"email\": \"security@example.com\"\n    }"},
+		{"issue", prism.Issues[0].Extra, "retest_notes", "null"},
+		{"host", prism.Issues[0].AffectedHosts[0].Extra, "mac_address", `"00:11:22:33:44:55"`},
+	}
+	for _, test := range tests {
+		got, ok := test.extra[test.key]
+		if !ok {
+			t.Errorf("%s: unknown key %q was not captured", test.level, test.key)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: %q = %s, want %s", test.level, test.key, got, test.want)
+		}
+	}
+
+	var got bytes.Buffer
+	if err = prism.Save(&got, SaveOptions{Indent: "  "}); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("round trip changed the file:\n%s", got.String())
+	}
+}
+
+func TestKnownFieldsIgnoreCase(t *testing.T) {
+	// encoding/json decodes "Name" into the name field, so it is not unknown
+	var issue Issue
+	if err := json.Unmarshal([]byte(`{"Name": "Open Ports", "FINDING": "<p>Open.</p>", "Retest_Notes": null}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Name != "Open Ports" || issue.Finding != "<p>Open.</p>" {
+		t.Errorf("name %q, finding %q, want the keys decoded whatever their case", issue.Name, issue.Finding)
+	}
+	if _, ok := issue.Extra["Retest_Notes"]; !ok || len(issue.Extra) != 1 {
+		t.Errorf("extra = %v, want only Retest_Notes", issue.Extra)
+	}
+
+	out, err := json.Marshal(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte(`"Name"`)) || bytes.Count(out, []byte(`"Open Ports"`)) != 1 {
+		t.Errorf("name written twice: %s", out)
+	}
+}
@@ -0,0 +1,230 @@
+package PrismDataStructs
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// cvssGrammar matches the vectors the schema's cvss_vector pattern allows
+var cvssGrammar = regexp.MustCompile(cvssPattern)
+
+// ProblemSeverity says whether a validation problem blocks an import
+type ProblemSeverity string
+
+const (
+	SeverityError   ProblemSeverity = "error"
+	SeverityWarning ProblemSeverity = "warning"
+)
+
+// Problem is a single finding from the validator
+type Problem struct {
+	Severity ProblemSeverity `json:"severity"`
+
+	// IssueIndex is the position of the issue in the file, nil for problems
+	// outside the issues array
+	IssueIndex *int   `json:"issue_index"`
+	IssueName  string `json:"issue_name,omitempty"`
+
+	// Path is a JSON path to the offending value, such as $.issues[3].affected_hosts[0].ip
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationReport is the machine readable result of validating a Prism file
+type ValidationReport struct {
+	Issues   int       `json:"issues"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Problems []Problem `json:"problems"`
+}
+
+// Valid reports whether the file has no errors; warnings are allowed
+func (r *ValidationReport) Valid() bool {
+	return r.Errors == 0
+}
+
+// Add records a problem and updates the counts
+func (r *ValidationReport) Add(problem Problem) {
+	switch problem.Severity {
+	case SeverityError:
+		r.Errors++
+	case SeverityWarning:
+		r.Warnings++
+	}
+	r.Problems = append(r.Problems, problem)
+}
+
+// Validator checks a Prism file piece by piece, so it can be fed from a
+// Reader without holding the whole file in memory. Types, required fields,
+// the risk rating vocabulary, the CVSS grammar, date formats and port ranges
+// come from prism.schema.json; the checks that a schema cannot express, such
+// as whether a CVSS vector's metrics and values are valid, are done here.
+type Validator struct {
+	report ValidationReport
+	names  map[string]int
+	schema *schemaChecker
+}
+
+// NewValidator returns an empty Validator
+func NewValidator() *Validator {
+	v := &Validator{
+		report: ValidationReport{Problems: []Problem{}},
+		names:  make(map[string]int),
+	}
+
+	schema, err := embeddedSchema()
+	if err != nil {
+		v.report.Add(Problem{Severity: SeverityError, Path: "$", Message: err.Error()})
+	}
+	v.schema = schema
+	return v
+}
+
+// Validate checks a whole project in one call
+func Validate(p *Prism) *ValidationReport {
+	v := NewValidator()
+	for issueIndex := range p.Issues {
+		v.Issue(issueIndex, &p.Issues[issueIndex])
+	}
+	v.Header(p)
+	return v.Report()
+}
+
+// Report returns everything found so far
+func (v *Validator) Report() *ValidationReport {
+	return &v.report
+}
+
+// Header checks the top level fields of the file, its issues are ignored
+func (v *Validator) Header(p *Prism) {
+	header := *p
+	header.Issues = nil
+	v.checkSchema(nil, "", "Prism", header, "$")
+
+	if p.Phase.Name != "" && p.Phase.StartDate == "" {
+		v.report.Add(Problem{Severity: SeverityWarning, Path: "$.phase.start_date", Message: "date is empty"})
+	}
+}
+
+// Issue checks a single issue found at index in the issues array
+func (v *Validator) Issue(index int, issue *Issue) {
+	v.report.Issues++
+	path := fmt.Sprintf("$.issues[%d]", index)
+	add := func(severity ProblemSeverity, field, format string, args ...interface{}) {
+		v.report.Add(Problem{
+			Severity:   severity,
+			IssueIndex: &index,
+			IssueName:  issue.Name,
+			Path:       path + field,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Schema rules, with tolerant numbers in their canonical form so that
+	// port ranges apply to numeric strings too
+	canonical := *issue
+	canonical.AffectedHosts = append([]AffectedHost(nil), issue.AffectedHosts...)
+	canonical.SetNumericForm(FormNumber)
+	v.checkSchema(&index, issue.Name, "Issue", canonical, path)
+
+	if strings.TrimSpace(issue.Status) == "" {
+		add(SeverityWarning, ".status", "status is empty")
+	}
+
+	// Duplicate names
+	if name := strings.ToLower(strings.TrimSpace(issue.Name)); name != "" {
+		if first, ok := v.names[name]; ok {
+			add(SeverityWarning, ".name", "duplicate issue name, first used by issues[%d]", first)
+		} else {
+			v.names[name] = index
+		}
+	}
+
+	// CVSS vector. The schema only checks the grammar, so a vector that fits
+	// it is parsed too, to agree with the fixer and Issue.CVSS.
+	if issue.CvssVector != nil {
+		if cvssGrammar.MatchString(*issue.CvssVector) {
+			if _, err := ParseCVSS(*issue.CvssVector); err != nil {
+				add(SeverityError, ".cvss_vector", "%v", err)
+			}
+		}
+		if strings.HasSuffix(*issue.CvssVector, "/") {
+			add(SeverityWarning, ".cvss_vector", "%q has a trailing slash", *issue.CvssVector)
+		}
+	}
+
+	// Dates
+	if issue.ConfirmedAt == "" {
+		add(SeverityWarning, ".confirmed_at", "date is empty")
+	}
+
+	// Affected hosts
+	for hostIndex, host := range issue.AffectedHosts {
+		field := fmt.Sprintf(".affected_hosts[%d]", hostIndex)
+
+		switch {
+		case host.Ip == "" && host.Hostname == "" && (host.Name == nil || *host.Name == ""):
+			add(SeverityError, field, "affected host has no ip, hostname or name")
+		case host.Ip != "" && net.ParseIP(host.Ip) == nil:
+			add(SeverityWarning, field+".ip", "%q is not an IP address", host.Ip)
+		}
+
+		if host.Protocol != nil && *host.Protocol != "" {
+			switch strings.ToLower(*host.Protocol) {
+			case "tcp", "udp", "icmp":
+			default:
+				add(SeverityWarning, field+".protocol", "unexpected protocol %q", *host.Protocol)
+			}
+		}
+	}
+}
+
+// DecodeError records an issue the Reader could not decode, so it is counted
+// and reported at the value that failed while the rest of the file is checked
+func (v *Validator) DecodeError(err *IssueDecodeError) {
+	v.report.Issues++
+	index := err.Index
+	v.report.Add(Problem{
+		Severity:   SeverityError,
+		IssueIndex: &index,
+		IssueName:  err.Name,
+		Path:       "$." + err.Path,
+		Message:    err.Err.Error(),
+	})
+}
+
+// checkSchema validates value against a definition of prism.schema.json and
+// records every violation as an error
+func (v *Validator) checkSchema(index *int, name, definition string, value interface{}, path string) {
+	if v.schema == nil {
+		return
+	}
+
+	raw, err := marshalUnescaped(value)
+	if err == nil {
+		value, err = decodeGeneric(raw)
+	}
+	if err != nil {
+		v.report.Add(Problem{Severity: SeverityError, IssueIndex: index, IssueName: name, Path: path, Message: err.Error()})
+		return
+	}
+
+	var violations []schemaViolation
+	if definition == "Prism" {
+		v.schema.check(v.schema.root, value, path, &violations)
+	} else {
+		violations = v.schema.checkDefinition(definition, value, path)
+	}
+
+	for _, violation := range violations {
+		v.report.Add(Problem{
+			Severity:   SeverityError,
+			IssueIndex: index,
+			IssueName:  name,
+			Path:       violation.Path,
+			Message:    violation.Message,
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package PrismDataStructs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	prism, err := LoadFile(filepath.Join("testdata", "nessus-id-number.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := Validate(prism); report.Errors != 0 || report.Warnings != 0 {
+		t.Fatalf("clean fixture reported problems: %+v", report.Problems)
+	}
+
+	broken := prism.Issues[0]
+	broken.Summary = nil
+	broken.OriginalRiskRating = "Extreme"
+	vector := "CVSS:3.1/AV:X"
+	broken.CvssVector = &vector
+	broken.ConfirmedAt = "02/05/2023"
+	broken.AffectedHosts = []AffectedHost{{Ip: "10.0.0.1", Port: NewPort(70000)}}
+	prism.Issues = append(prism.Issues, broken)
+
+	report := Validate(prism)
+	want := map[string]ProblemSeverity{
+		"$.issues[1].summary":                SeverityError,
+		"$.issues[1].original_risk_rating":   SeverityError,
+		"$.issues[1].cvss_vector":            SeverityError,
+		"$.issues[1].confirmed_at":           SeverityError,
+		"$.issues[1].affected_hosts[0].port": SeverityError,
+		"$.issues[1].name":                   SeverityWarning,
+	}
+	got := make(map[string]ProblemSeverity)
+	for _, problem := range report.Problems {
+		got[problem.Path] = problem.Severity
+		if problem.IssueIndex == nil || *problem.IssueIndex != 1 {
+			t.Errorf("%s: issue index = %v, want 1", problem.Path, problem.IssueIndex)
+		}
+	}
+	for path, severity := range want {
+		if got[path] != severity {
+			t.Errorf("%s: severity = %q, want %q", path, got[path], severity)
+		}
+	}
+	if report.Valid() {
+		t.Error("report with errors should not be valid")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+var (
+	green = color.New(color.FgGreen)
+	red   = color.New(color.FgRed)
+
+	// quiet hides progress messages, set by the global -q flag
+	quiet bool
+)
+
+// logger writes a command's messages to stderr, keeping stdout free for
+// output, and counts warnings so the command can exit with exitWarnings. It
+// is safe to use from several goroutines.
+type logger struct {
+	name string
+
+	mu       sync.Mutex
+	warnings int
+}
+
+func newLogger(name string) *logger {
+	return &logger{name: "prism " + name}
+}
+
+// Infof reports progress, such as a fix being applied
+func (l *logger) Infof(format string, args ...interface{}) {
+	if !quiet {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		green.Fprintf(os.Stderr, "[+] "+format+"\n", args...)
+	}
+}
+
+// Warnf reports a problem that does not stop the command
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings++
+	red.Fprintf(os.Stderr, "[-] "+format+"\n", args...)
+}
+
+// Fail reports the error that stopped the command and returns exitFailure
+func (l *logger) Fail(err error) int {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", l.name, err)
+	return exitFailure
+}
+
+// Failf is Fail with a formatted message
+func (l *logger) Failf(format string, args ...interface{}) int {
+	return l.Fail(fmt.Errorf(format, args...))
+}
+
+// ExitCode is exitWarnings if anything was warned about, otherwise exitOK
+func (l *logger) ExitCode() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.warnings > 0 {
+		return exitWarnings
+	}
+	return exitOK
+}
+
+// ioFlags are the -i and -o flags every command that reads or writes a file
+// shares. "-" means stdin or stdout.
+type ioFlags struct {
+	input  *string
+	output *string
+}
+
+func addIOFlags(flags *flag.FlagSet, inputUsage, outputUsage string) ioFlags {
+	return ioFlags{
+		input:  flags.String("i", "", inputUsage+` ("-" for stdin)`),
+		output: flags.String("o", "", outputUsage+` ("-" or empty for stdout)`),
+	}
+}
+
+// openInput opens the -i file, which is required
+func (f ioFlags) openInput() (io.ReadCloser, error) {
+	if *f.input == "" {
+		return nil, fmt.Errorf("no input file specified (-i)")
+	}
+	return openFile(*f.input)
+}
+
+// createOutput creates the -o file, or returns stdout
+func (f ioFlags) createOutput() (io.WriteCloser, error) {
+	return createFile(*f.output)
+}
+
+// openFile opens path for reading, or returns stdin for "-"
+func openFile(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// createFile creates path, or returns stdout for "-" or no path
+func createFile(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	outputFile := flags.String("o", "", `File to write to ("-" or empty for stdout)`)
+	format := flags.String("format", "text", "Output format: text, json or prism")
+	identity := flags.String("identity", identityList(PrismDataStructs.DefaultIssueIdentities), "Comma separated issue identities to match on: name, nessus-id, nuclei-template, cves")
+	markResolved := flags.Bool("mark-resolved", false, "Set the status and remediated date of resolved issues in the Prism output")
+	resolvedStatus := flags.String("resolved-status", "remediated", "Status for resolved issues with -mark-resolved")
+	remediatedAt := flags.String("remediated-at", time.Now().Format("2006-01-02"), "Remediated date for resolved issues with -mark-resolved")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: prism diff [flags] original.json retest.json\n\nOne of the files may be \"-\" for stdin.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	log := newLogger("diff")
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitFailure
+	}
+	if *format != "text" && *format != "json" && *format != "prism" {
+		return log.Failf("unknown format %q", *format)
+	}
+
+	identities, err := PrismDataStructs.ParseIssueIdentities(*identity)
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	before, err := loadFile(flags.Arg(0))
+	if err != nil {
+		return log.Failf("%s: %v", flags.Arg(0), err)
+	}
+	after, err := loadFile(flags.Arg(1))
+	if err != nil {
+		return log.Failf("%s: %v", flags.Arg(1), err)
+	}
+
+	changes := PrismDataStructs.Diff(before, after, identities...)
+
+	out, err := createFile(*outputFile)
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	switch *format {
+	case "prism":
+		status, date := "", ""
+		if *markResolved {
+			status, date = *resolvedStatus, *remediatedAt
+		}
+		err = changes.Prism(status, date).Save(out, PrismDataStructs.DefaultSaveOptions)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(changes)
+	default:
+		err = printChangeSet(out, changes)
+	}
+	if err != nil {
+		return log.Fail(err)
+	}
+	return exitOK
+}
+
+// loadFile loads a whole Prism file, or stdin for "-"
+func loadFile(path string) (*PrismDataStructs.Prism, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return PrismDataStructs.Load(f)
+}
+
+func printChangeSet(w io.Writer, changes *PrismDataStructs.ChangeSet) error {
+	var b strings.Builder
+
+	issues := func(title, marker string, list []PrismDataStructs.IssueChange) {
+		fmt.Fprintf(&b, "%s (%d)\n", title, len(list))
+		for _, change := range list {
+			fmt.Fprintf(&b, "  %s %-8s %s: %s\n", marker, change.Rating, change.Issue, strings.Join(change.Hosts, ", "))
+		}
+	}
+	hosts := func(title, marker string, list []PrismDataStructs.HostChange) {
+		fmt.Fprintf(&b, "%s (%d)\n", title, len(list))
+		for _, change := range list {
+			fmt.Fprintf(&b, "  %s %s: %s\n", marker, change.Issue, change.Host)
+		}
+	}
+
+	issues("New", "+", changes.New)
+	issues("Resolved", "-", changes.Resolved)
+	issues("Persisting", "=", changes.Persisting)
+	hosts("Hosts added", "+", changes.HostAdded)
+	hosts("Hosts removed", "-", changes.HostRemoved)
+
+	fmt.Fprintf(&b, "Ratings changed (%d)\n", len(changes.RatingChanged))
+	for _, change := range changes.RatingChanged {
+		fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", change.Issue, change.From, change.To)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+var (
+	// Remove plugin notes
+	noteRegex = regexp.MustCompile(`<br />Note that this plugin.*</p>`)
+
+	// Fixed version : [0-9\.]+?</p>
+	fixedVersionRegex = regexp.MustCompile(`(Should be|Fixed version)\s*:\s*[A-Za-z0-9\.\-_]*(\\u003c|\<)?`)
+
+	// Multiple newlines
+	newLineRegex = regexp.MustCompile(`(\<br\s?\/\>\<br\s?\/\>\<\/p\>|\\u003cbr\s?\/\\u003e\\u003cbr\s?\/\\u003e\\u003c\/p\\u003e)`)
+
+	// Check for "remote"
+	badStrings = map[string]string{
+		"remote service is":         "service was",
+		"remote server":             "server",
+		"remote web server":         "web server",
+		"The remote":                "The",
+		"remote host":               "host",
+		"is affected":               "was affected",
+		"service allows":            "service allowed",
+		"service supports":          "service supported",
+		"host supports":             "host supported",
+		"algorithms are supported":  "algorithms were supported",
+		"algorithms are enabled":    "algorithms were enabled",
+		"It is, therefore,":         "It was, therefore,",
+		"certificate has already":   "certificate had already",
+		"service ends":              "service ended",
+		"service encrypts":          "service encrypted",
+		"service accepts":           "service accepted",
+		"host allows":               "host allowed",
+		"server allows":             "server allowed",
+		"is prior":                  "was prior",
+		"host is":                   "host was",
+		"is running":                "was running",
+		"is installed":              "was installed",
+		"host contains":             "host contained",
+		"Tenable Network Security":  "Rootshell Security",
+		"host seems":                "host seemed",
+		"server uses":               "server used",
+		"server discloses":          "server disclosed",
+		"This plugin checks expiry": "Rootshell checked the expiry",
+		"it is signed":              "it was signed",
+	}
+)
+
+func runFix(args []string) int {
+	flags := flag.NewFlagSet("fix", flag.ExitOnError)
+	files := addIOFlags(flags, "Prism file to fix", "File to write the fixed issues to")
+	fixCVSS := flags.Bool("cvss", false, "Map the CVSS vector with the Severity of the issue")
+	writeExecSummary := flags.Bool("exec", false, "Write the Executive Summary (Warning! This will overwrite the existing Executive Summary!) Also requires the ChatGPT API key to be set in the CHATGPT_API_KEY environment variable.")
+	flags.Parse(args)
+
+	log := newLogger("fix")
+
+	fix := &fixTransform{log: log, fixCVSS: *fixCVSS}
+	if err := transformFile(files, fix); err != nil {
+		return log.Fail(err)
+	}
+
+	if *writeExecSummary {
+		// godotenv.Load()
+
+		// apiKey := os.Getenv("CHATGPT_API_KEY")
+		// if apiKey == "" {
+		// 	log.Fatalln("Missing API KEY")
+		// }
+
+		// ctx := context.Background()
+		// client := gpt3.NewClient(apiKey)
+		// client.Engine(ctx, gpt3.TextDavinci003Engine)
+
+		question := "Explain, in an executive summary format using paragraphs, the following vulnerabilities: " + strings.Join(fix.highIssues, ", ")
+
+		log.Infof("Request the following from ChatGPT: %s", question)
+
+		// resp, err := client.Completion(ctx, gpt3.CompletionRequest{
+		// 	Prompt:      []string{question},
+		// 	MaxTokens:   gpt3.IntPtr(1000),
+		// 	Temperature: gpt3.Float32Ptr(0),
+		// 	Stop:        []string{"\r\n\r\n"},
+		// })
+
+		// if err != nil {
+		// 	log.Println(err)
+		// }
+
+		// fmt.Println(resp.Choices[0].Text)
+	}
+
+	return log.ExitCode()
+}
+
+// fixTransform applies fixIssue to every issue, noting the High and Critical
+// issues for the executive summary
+type fixTransform struct {
+	log     *logger
+	fixCVSS bool
+
+	// imported issues come straight from a scanner, so what the scanner did
+	// not report, such as references or a CVSS vector, is printed as
+	// progress rather than counted as a warning
+	imported bool
+
+	highIssues []string
+}
+
+func (t *fixTransform) Apply(issue *PrismDataStructs.Issue) (*PrismDataStructs.Issue, error) {
+	if issue.OriginalRiskRating.AtLeast(PrismDataStructs.RiskHigh) {
+		t.highIssues = append(t.highIssues, issue.Name)
+	}
+	missing := t.log.Warnf
+	if t.imported {
+		missing = t.log.Infof
+	}
+	fixIssue(t.log, issue, t.fixCVSS, missing)
+	return issue, nil
+}
+
+// truncateCves keeps the issue's CVE list within what Prism accepts. Every
+// command that builds CVE lists, importing, fixing or merging, applies it.
+func truncateCves(log *logger, issue *PrismDataStructs.Issue) {
+	if dropped := issue.TruncateCves(); dropped > 0 {
+		log.Infof("Removed %d CVEs to keep the CVE list of %s within %d characters", dropped, issue.Name, PrismDataStructs.MaxCvesLength)
+	}
+}
+
+// fixIssue applies every clean-up to a single issue in place, warning about
+// anything it cannot fix and reporting what the issue lacks through missing
+func fixIssue(log *logger, issue *PrismDataStructs.Issue, fixCVSS bool, missing func(format string, args ...interface{})) {
+
+	truncateCves(log, issue)
+
+	// Remove plugin notes
+	if issue.Summary != nil {
+		*issue.Summary = noteRegex.ReplaceAllString(*issue.Summary, "</p>")
+	} else {
+		missing("No summary found for issue: %s", issue.Name)
+	}
+
+	// Check the technical details for "Tenable ciphername" and replace it with "Ciphername"
+	if strings.Contains(issue.TechnicalDetails, "Tenable ciphername") {
+		log.Infof("Found Tenable ciphername in technical details, updating...")
+		issue.TechnicalDetails = strings.ReplaceAll(issue.TechnicalDetails, "Tenable ciphername", "Ciphername")
+	}
+
+	// Perform a regex lookup on the technical details to check for Fixed version : [0-9\.]+?</p> and remove it
+	if fixedVersionRegex.MatchString(issue.TechnicalDetails) {
+		log.Infof("Found \"Fixed version\" in technical details, updating...")
+		issue.TechnicalDetails = fixedVersionRegex.ReplaceAllString(issue.TechnicalDetails, "$2")
+	}
+
+	// Perform a regex lookup on the technical details to remove multiple newlines
+	if newLineRegex.MatchString(issue.TechnicalDetails) {
+		issue.TechnicalDetails = newLineRegex.ReplaceAllString(issue.TechnicalDetails, "</p>")
+	}
+
+	// Remove unwanted empty paragraph tags
+	issue.TechnicalDetails = strings.ReplaceAll(issue.TechnicalDetails, "<p></p>", "")
+
+	for badString, goodString := range badStrings {
+
+		// Check the finding
+		if strings.Contains(issue.Finding, badString) {
+			log.Infof("Found %q in finding, replacing with %q...", badString, goodString)
+			issue.Finding = strings.ReplaceAll(issue.Finding, badString, goodString)
+		}
+
+		// Check the summary
+		if issue.Summary != nil && strings.Contains(*issue.Summary, badString) {
+			log.Infof("Found %q in summary, replacing with %q...", badString, goodString)
+			*issue.Summary = strings.ReplaceAll(*issue.Summary, badString, goodString)
+		}
+
+		// Check the Technical Details
+		if strings.Contains(issue.TechnicalDetails, badString) {
+			log.Infof("Found %q in technical details, replacing with %q...", badString, goodString)
+			issue.TechnicalDetails = strings.ReplaceAll(issue.TechnicalDetails, badString, goodString)
+		}
+
+		// Check the recommendation
+		if issue.Recommendation != nil && strings.Contains(*issue.Recommendation, badString) {
+			log.Infof("Found %q in recommendation, replacing with %q...", badString, goodString)
+			*issue.Recommendation = strings.ReplaceAll(*issue.Recommendation, badString, goodString)
+		}
+	}
+
+	if issue.References != nil {
+		var wg sync.WaitGroup
+
+		// Check the references for "nessus.org/u?" and replace it with the redirect URL
+		for refIndex := range issue.References {
+			wg.Add(1)
+			go func(refIndex int) {
+				defer wg.Done()
+				reference := issue.References[refIndex]
+				if strings.Contains(reference, "nessus.org/u?") || strings.Contains(reference, "api.tenable.com/v1/u?") {
+					// Do a HTTP request to the URL and get the redirect URL
+					resp, err := http.Get(reference)
+					if err != nil {
+						log.Warnf("Could not resolve reference %s: %v", reference, err)
+						return
+					}
+					resp.Body.Close()
+
+					// Get the redirect URL
+					updatedReference := resp.Request.URL.String()
+					log.Infof("Fixing Reference URL: %s -> %s", reference, updatedReference)
+					issue.References[refIndex] = updatedReference
+				}
+			}(refIndex)
+		}
+
+		// Wait for this issue's references before it is written out
+		wg.Wait()
+	} else {
+		missing("No references found for issue: %s", issue.Name)
+	}
+
+	// Check the CVSS score
+	cvss, err := issue.CVSS()
+	switch {
+	case errors.Is(err, PrismDataStructs.ErrNoCVSS):
+		missing("No CVSS score found for issue: %s", issue.Name)
+	case err != nil:
+		log.Warnf("%v for issue: %s", err, issue.Name)
+	case cvss.Version == PrismDataStructs.CVSSv30 || cvss.Version == PrismDataStructs.CVSSv31:
+
+		// Re-score CVSS:3.0 vectors as CVSS:3.1
+		cvss, err = cvss.Upgrade()
+		if err != nil {
+			log.Warnf("%v for issue: %s", err, issue.Name)
+			break
+		}
+		*issue.CvssVector = cvss.Vector
+
+		if fixCVSS && cvss.Severity != issue.OriginalRiskRating {
+			log.Infof("Fixing Severity: %s -> %s", issue.OriginalRiskRating, cvss.Severity)
+			issue.OriginalRiskRating = cvss.Severity
+		}
+	default:
+		*issue.CvssVector = strings.TrimRight(*issue.CvssVector, "/")
+	}
+
+	if issue.Recommendation == nil {
+		missing("No recommendation found for issue: %s", issue.Name)
+	} else if !strings.HasPrefix(strings.TrimPrefix(*issue.Recommendation, "<p>"), "It is recommended ") {
+		missing("Recommendation does not start with 'It is recommended ' for issue: %s", issue.Name)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFixImportedIssuesMissingFields(t *testing.T) {
+	// The Qualys fixture has issues without references or a CVSS vector,
+	// which prism fix warns about but an import only notes
+	for _, test := range []struct {
+		imported bool
+		want     int
+	}{
+		{imported: false, want: exitWarnings},
+		{imported: true, want: exitOK},
+	} {
+		prism := importFixture(t, "qualys", "qualys.xml")
+		log := newLogger("test")
+		fix := &fixTransform{log: log, imported: test.imported}
+		for i := range prism.Issues {
+			if _, err := fix.Apply(&prism.Issues[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if code := log.ExitCode(); code != test.want {
+			t.Errorf("imported %v: exit code %d, want %d", test.imported, code, test.want)
+		}
+	}
+}
@@ -1,15 +1,19 @@
-module github.com/MantisSTS/PrismTools/GoFuckery
+module github.com/MantisSTS/PrismTools/prism
 
 go 1.19
 
 require (
+	github.com/MantisSTS/PrismTools/PrismDataStructs v0.0.0
 	github.com/fatih/color v1.13.0
-	github.com/goark/go-cvss v1.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/goark/errs v1.1.0 // indirect
+	github.com/goark/go-cvss v1.3.0 // indirect
 	github.com/mattn/go-colorable v0.1.9 // indirect
 	github.com/mattn/go-isatty v0.0.14 // indirect
 	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
 )
+
+replace github.com/MantisSTS/PrismTools/PrismDataStructs => ../PrismDataStructs
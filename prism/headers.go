@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// OWASP Secure Headers Project lists of headers to set and to remove
+const (
+	headersAddURL    = "https://owasp.org/www-project-secure-headers/ci/headers_add.json"
+	headersRemoveURL = "https://owasp.org/www-project-secure-headers/ci/headers_remove.json"
+)
+
+type AddHeaders struct {
+	LastUpdateUTC string   `json:"last_update_utc"`
+	Headers       []Header `json:"headers"`
+}
+
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type RemoveHeaders struct {
+	LastUpdateUTC string   `json:"last_update_utc"`
+	Headers       []string `json:"headers"`
+}
+
+func runHeaders(args []string) int {
+	flags := flag.NewFlagSet("headers", flag.ExitOnError)
+	files := addIOFlags(flags, "File of URLs to check, one per line (default stdin)", "File to write the findings to")
+	threads := flags.Int("t", 10, "Number of threads to use")
+	flags.Parse(args)
+
+	log := newLogger("headers")
+
+	// URLs are usually piped in from another tool
+	if *files.input == "" {
+		*files.input = "-"
+	}
+
+	var headersToAdd AddHeaders
+	if err := fetchJSON(headersAddURL, &headersToAdd); err != nil {
+		return log.Fail(err)
+	}
+	var headersToRemove RemoveHeaders
+	if err := fetchJSON(headersRemoveURL, &headersToRemove); err != nil {
+		return log.Fail(err)
+	}
+
+	in, err := files.openInput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer in.Close()
+
+	out, err := files.createOutput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	// Ignore SSL certificates
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	jobQueue := make(chan string, 100)
+	resultsQueue := make(chan []string, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < *threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobQueue {
+				res, err := checkHeaders(client, url, headersToAdd, headersToRemove)
+				if err != nil {
+					log.Warnf("%s: %v", url, err)
+					continue
+				}
+				if len(res) > 0 {
+					resultsQueue <- res
+				}
+			}
+		}()
+	}
+
+	// Write results as they arrive so a long list of URLs cannot fill the queue
+	written := make(chan error)
+	go func() {
+		var err error
+		for res := range resultsQueue {
+			for _, line := range res {
+				if err == nil {
+					_, err = fmt.Fprintln(out, line)
+				}
+			}
+		}
+		written <- err
+	}()
+
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		if url := strings.TrimSpace(sc.Text()); url != "" {
+			jobQueue <- url
+		}
+	}
+	close(jobQueue)
+
+	wg.Wait()
+	close(resultsQueue)
+
+	if err = <-written; err != nil {
+		return log.Fail(err)
+	}
+	if err = sc.Err(); err != nil {
+		return log.Fail(err)
+	}
+	return log.ExitCode()
+}
+
+// fetchJSON decodes the JSON document at url into v
+func fetchJSON(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Check the status code
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// checkHeaders reports every recommended header url does not set, or sets to
+// another value, and every header it sets that should be removed
+func checkHeaders(client *http.Client, url string, headersToAdd AddHeaders, headersToRemove RemoveHeaders) ([]string, error) {
+	var output []string
+
+	// Check the headers in the response
+	resp, err := client.Head(url)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	for _, OSHPHeader := range headersToAdd.Headers {
+		values := resp.Header.Values(OSHPHeader.Name)
+		if len(values) == 0 {
+			output = append(output, fmt.Sprintf("URL: %s | Header %s is not set", url, OSHPHeader.Name))
+		} else if values[0] != OSHPHeader.Value {
+			output = append(output, fmt.Sprintf("URL: %s | Header %s (%s) is not set to %s", url, OSHPHeader.Name, values, OSHPHeader.Value))
+		}
+	}
+
+	for _, name := range headersToRemove.Headers {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			output = append(output, fmt.Sprintf("URL: %s | Header %s (%s) should be removed", url, name, values))
+		}
+	}
+
+	return output, nil
+}
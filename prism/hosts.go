@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func runHostsRemove(args []string) int {
+	flags := flag.NewFlagSet("hosts remove", flag.ExitOnError)
+	files := addIOFlags(flags, "Prism file to remove hosts from", "File to write the remaining issues to")
+	hostFile := flags.String("f", "", "File containing hosts to remove, one per line")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: prism hosts remove -i prism.json [-o out] [-f hosts.txt] [host ...]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Hosts are an address or hostname, optionally with a port and protocol such as 10.0.0.1:443/tcp.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	log := newLogger("hosts remove")
+
+	var hostsToRemove []PrismDataStructs.HostKey
+	for _, arg := range flags.Args() {
+		hostKey, err := PrismDataStructs.ParseHostKey(arg)
+		if err != nil {
+			return log.Fail(err)
+		}
+		hostsToRemove = append(hostsToRemove, hostKey)
+	}
+	if *hostFile != "" {
+		fromFile, err := readHostFile(*hostFile)
+		if err != nil {
+			return log.Fail(err)
+		}
+		hostsToRemove = append(hostsToRemove, fromFile...)
+	}
+	if len(hostsToRemove) == 0 {
+		return log.Failf("no hosts to remove (-f or arguments)")
+	}
+
+	remove := &hostsRemoveTransform{log: log, hosts: hostsToRemove}
+	if err := transformFile(files, remove); err != nil {
+		return log.Fail(err)
+	}
+	return log.ExitCode()
+}
+
+// hostsRemoveTransform drops the matching hosts from every issue, and drops
+// issues left without hosts
+type hostsRemoveTransform struct {
+	log   *logger
+	hosts []PrismDataStructs.HostKey
+}
+
+func (t *hostsRemoveTransform) Apply(issue *PrismDataStructs.Issue) (*PrismDataStructs.Issue, error) {
+	// Remove the issue if there are no more hosts
+	if !removeHosts(t.log, issue, t.hosts) {
+		t.log.Infof("Removing issue as it only affected your hosts: %s", issue.Name)
+		return nil, nil
+	}
+	return issue, nil
+}
+
+// readHostFile reads a file of hosts to remove. Each line is an address or
+// hostname, optionally with a port and protocol such as 10.0.0.1:443/tcp.
+func readHostFile(path string) ([]PrismDataStructs.HostKey, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hosts []PrismDataStructs.HostKey
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hostKey, err := PrismDataStructs.ParseHostKey(line)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, hostKey)
+	}
+	return hosts, scanner.Err()
+}
+
+// removeHosts drops every affected host matching one of hostsToRemove and
+// reports whether the issue should be kept. The other hosts are kept as they
+// are and in order, even when several share a host key.
+func removeHosts(log *logger, issue *PrismDataStructs.Issue, hostsToRemove []PrismDataStructs.HostKey) bool {
+	if len(issue.AffectedHosts) == 0 {
+		return true
+	}
+
+	kept := make([]PrismDataStructs.AffectedHost, 0, len(issue.AffectedHosts))
+	for _, host := range issue.AffectedHosts {
+		if matchesAnyHost(host.Key(), hostsToRemove) {
+			log.Infof("Removing host %s from issue %s", host.Key(), issue.Name)
+			continue
+		}
+		kept = append(kept, host)
+	}
+
+	if len(kept) < len(issue.AffectedHosts) {
+		issue.AffectedHosts = kept
+	}
+	return len(kept) > 0
+}
+
+func matchesAnyHost(key PrismDataStructs.HostKey, patterns []PrismDataStructs.HostKey) bool {
+	for _, pattern := range patterns {
+		if key.Match(pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// nmapRun is the part of Nmap's XML output (nmap -oX) the enricher reads
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	Status struct {
+		State string `xml:"state,attr"`
+	} `xml:"status"`
+	Addresses []struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"hostnames>hostname"`
+	Ports     []nmapPort `xml:"ports>port"`
+	OSMatches []struct {
+		Name     string   `xml:"name,attr"`
+		Accuracy int      `xml:"accuracy,attr"`
+		Cpes     []string `xml:"osclass>cpe"`
+	} `xml:"os>osmatch"`
+}
+
+type nmapPort struct {
+	Protocol string `xml:"protocol,attr"`
+	Port     int    `xml:"portid,attr"`
+	State    struct {
+		State string `xml:"state,attr"`
+	} `xml:"state"`
+	Service struct {
+		Name    string   `xml:"name,attr"`
+		Product string   `xml:"product,attr"`
+		Version string   `xml:"version,attr"`
+		Tunnel  string   `xml:"tunnel,attr"`
+		Cpes    []string `xml:"cpe"`
+	} `xml:"service"`
+}
+
+// ip is the host's IPv4 or IPv6 address
+func (h *nmapHost) ip() string {
+	for _, address := range h.Addresses {
+		if address.AddrType == "ipv4" || address.AddrType == "ipv6" {
+			return address.Addr
+		}
+	}
+	return ""
+}
+
+// hostname prefers the reverse DNS name over the name given on the command line
+func (h *nmapHost) hostname() string {
+	for _, hostname := range h.Hostnames {
+		if hostname.Type == "PTR" {
+			return hostname.Name
+		}
+	}
+	if len(h.Hostnames) > 0 {
+		return h.Hostnames[0].Name
+	}
+	return ""
+}
+
+// os is the most accurate OS guess and its CPEs
+func (h *nmapHost) os() (string, []string) {
+	best := -1
+	for i, match := range h.OSMatches {
+		if best == -1 || match.Accuracy > h.OSMatches[best].Accuracy {
+			best = i
+		}
+	}
+	if best == -1 {
+		return "", nil
+	}
+	return h.OSMatches[best].Name, h.OSMatches[best].Cpes
+}
+
+// service is the service name, prefixed with ssl/ as Nmap prints it for
+// services behind TLS
+func (p *nmapPort) service() string {
+	if p.Service.Tunnel == "ssl" && p.Service.Name != "" {
+		return "ssl/" + p.Service.Name
+	}
+	return p.Service.Name
+}
+
+// product is the product and version Nmap identified, such as "nginx 1.18.0"
+func (p *nmapPort) product() string {
+	return strings.TrimSpace(p.Service.Product + " " + p.Service.Version)
+}
+
+// hostsEnrichTransform fills empty host metadata from an Nmap scan. With
+// openPorts it also flushes an informational issue listing every open port.
+type hostsEnrichTransform struct {
+	log       *logger
+	hosts     map[PrismDataStructs.HostKey]*nmapHost
+	ports     map[PrismDataStructs.HostKey]*nmapPort
+	scan      *nmapRun
+	openPorts bool
+	enriched  int
+}
+
+func newHostsEnrichTransform(log *logger, nmapFile string, openPorts bool) (*hostsEnrichTransform, error) {
+	f, err := os.Open(nmapFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var scan nmapRun
+	if err = xml.NewDecoder(f).Decode(&scan); err != nil {
+		return nil, fmt.Errorf("%s: %w", nmapFile, err)
+	}
+
+	t := &hostsEnrichTransform{
+		log:       log,
+		hosts:     make(map[PrismDataStructs.HostKey]*nmapHost),
+		ports:     make(map[PrismDataStructs.HostKey]*nmapPort),
+		scan:      &scan,
+		openPorts: openPorts,
+	}
+
+	// Index every host and open port by address and by hostname, so a Prism
+	// host recorded either way is found
+	for i := range scan.Hosts {
+		host := &scan.Hosts[i]
+		if host.Status.State == "down" {
+			continue
+		}
+
+		var names []PrismDataStructs.HostKey
+		if ip := host.ip(); ip != "" {
+			names = append(names, PrismDataStructs.NewHostKey(ip, "", 0, ""))
+		}
+		for _, hostname := range host.Hostnames {
+			names = append(names, PrismDataStructs.NewHostKey("", hostname.Name, 0, ""))
+		}
+
+		for _, name := range names {
+			t.hosts[name] = host
+			for j := range host.Ports {
+				port := &host.Ports[j]
+				if port.State.State != "open" {
+					continue
+				}
+				t.ports[PrismDataStructs.NewHostKey(name.IP, name.Hostname, port.Port, port.Protocol)] = port
+			}
+		}
+	}
+	return t, nil
+}
+
+// lookup finds the Nmap host and port for a Prism host, by address when it
+// has one and by hostname otherwise
+func (t *hostsEnrichTransform) lookup(host PrismDataStructs.AffectedHost) (*nmapHost, *nmapPort) {
+	key := host.Key()
+	name := PrismDataStructs.HostKey{IP: key.IP}
+	if key.IP == "" {
+		name = PrismDataStructs.HostKey{Hostname: key.Hostname}
+	}
+
+	nmap := t.hosts[name]
+	if nmap == nil {
+		return nil, nil
+	}
+	if key.Port == 0 {
+		return nmap, nil
+	}
+	name.Port, name.Protocol = key.Port, key.Protocol
+	return nmap, t.ports[name]
+}
+
+func (t *hostsEnrichTransform) Apply(issue *PrismDataStructs.Issue) (*PrismDataStructs.Issue, error) {
+	for i := range issue.AffectedHosts {
+		host := &issue.AffectedHosts[i]
+		nmap, port := t.lookup(*host)
+		if nmap == nil {
+			continue
+		}
+		enrichHost(host, nmap, port)
+		t.enriched++
+	}
+	return issue, nil
+}
+
+// enrichHost fills the fields of host that are empty, and adds any CPEs it
+// does not have
+func enrichHost(host *PrismDataStructs.AffectedHost, nmap *nmapHost, port *nmapPort) {
+	if host.Hostname == "" {
+		host.Hostname = nmap.hostname()
+	}
+
+	osName, osCpes := nmap.os()
+	if host.OperatingSystem == nil && osName != "" {
+		host.OperatingSystem = &osName
+	}
+	cpes := append([]string(nil), osCpes...)
+
+	if port != nil {
+		if service := port.service(); host.Service == nil && service != "" {
+			host.Service = &service
+		}
+		cpes = append(cpes, port.Service.Cpes...)
+	}
+
+	if len(cpes) > 0 {
+		var all []string
+		if host.Cpes != nil {
+			all = *host.Cpes
+		}
+		for _, cpe := range cpes {
+			if !containsString(all, cpe) {
+				all = append(all, cpe)
+			}
+		}
+		host.Cpes = &all
+	}
+}
+
+// Flush returns the open ports issue, when asked for
+func (t *hostsEnrichTransform) Flush() ([]*PrismDataStructs.Issue, error) {
+	t.log.Infof("Enriched %d affected hosts from Nmap", t.enriched)
+	if !t.openPorts {
+		return nil, nil
+	}
+
+	issue := &PrismDataStructs.Issue{
+		Name:               "Open Ports",
+		Finding:            "<p>The following ports were found to be open. Each open port exposes a service to attack, so only the services required for the host's role should be reachable.</p>",
+		OriginalRiskRating: PrismDataStructs.RiskInfo,
+		Status:             "open",
+		ConfirmedAt:        time.Now().Format("2006-01-02"),
+	}
+	summary := "<p>Open ports were identified on the scanned hosts.</p>"
+	issue.Summary = &summary
+	recommendation := "<p>It is recommended that the open ports are reviewed and that any services that are not required are disabled or restricted by a firewall.</p>"
+	issue.Recommendation = &recommendation
+
+	hosts := PrismDataStructs.NewHostSet()
+	rows := [][]string{{"Host", "Port", "Service", "Product"}}
+	for i := range t.scan.Hosts {
+		nmap := &t.scan.Hosts[i]
+		if nmap.Status.State == "down" {
+			continue
+		}
+		for j := range nmap.Ports {
+			port := &nmap.Ports[j]
+			if port.State.State != "open" {
+				continue
+			}
+
+			host := PrismDataStructs.AffectedHost{
+				Ip:   nmap.ip(),
+				Port: PrismDataStructs.NewPort(port.Port),
+			}
+			protocol := port.Protocol
+			host.Protocol = &protocol
+			if host.Ip == "" {
+				host.Ip = nmap.hostname()
+			}
+			enrichHost(&host, nmap, port)
+			if hosts.Add(host) {
+				key := host.Key()
+				rows = append(rows, []string{key.Address(), strconv.Itoa(port.Port) + "/" + port.Protocol, port.service(), port.product()})
+			}
+		}
+	}
+	if hosts.Len() == 0 {
+		return nil, nil
+	}
+
+	issue.AffectedHosts = hosts.Hosts()
+	issue.TechnicalDetails = htmlTable(rows...)
+	return []*PrismDataStructs.Issue{issue}, nil
+}
+
+func runHostsEnrich(args []string) int {
+	flags := flag.NewFlagSet("hosts enrich", flag.ExitOnError)
+	files := addIOFlags(flags, "Prism file to enrich", "File to write the enriched issues to")
+	nmapFile := flags.String("nmap", "", "Nmap XML output (nmap -oX)")
+	openPorts := flags.Bool("open-ports", false, "Add an informational issue listing every open port")
+	flags.Parse(args)
+
+	log := newLogger("hosts enrich")
+
+	if *nmapFile == "" {
+		return log.Failf("no Nmap file specified (-nmap)")
+	}
+
+	enrich, err := newHostsEnrichTransform(log, *nmapFile, *openPorts)
+	if err != nil {
+		return log.Fail(err)
+	}
+	if err = transformFile(files, enrich); err != nil {
+		return log.Fail(err)
+	}
+	return log.ExitCode()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestHostsEnrich(t *testing.T) {
+	enrich, err := newHostsEnrichTransform(newLogger("test"), filepath.Join("testdata", "nmap.xml"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := "https"
+	issue := &PrismDataStructs.Issue{Name: "Test", AffectedHosts: []PrismDataStructs.AffectedHost{
+		{Ip: "10.0.0.1", Port: PrismDataStructs.NewPort(443), Service: &service},
+		{Ip: "10.0.0.1", Port: PrismDataStructs.NewPort(8080)},
+		{Ip: "10.0.0.2", Port: PrismDataStructs.NewPort(22)},
+	}}
+	if _, err = enrich.Apply(issue); err != nil {
+		t.Fatal(err)
+	}
+
+	web := issue.AffectedHosts[0]
+	if web.Hostname != "web01.example.com" || web.OperatingSystem == nil || *web.OperatingSystem != "Linux 5.4" {
+		t.Errorf("hostname %q, operating system %v, want the PTR name and the most accurate guess", web.Hostname, web.OperatingSystem)
+	}
+	if *web.Service != "https" {
+		t.Errorf("service = %q, want the existing service kept", *web.Service)
+	}
+	if web.Cpes == nil || !reflect.DeepEqual(*web.Cpes, []string{"cpe:/o:linux:linux_kernel:5.4", "cpe:/a:igor_sysoev:nginx:1.18.0"}) {
+		t.Errorf("cpes = %v", web.Cpes)
+	}
+
+	if closed := issue.AffectedHosts[1]; closed.Hostname != "web01.example.com" || closed.Service != nil {
+		t.Errorf("closed port = %+v, want the host filled but no service", closed)
+	}
+	if down := issue.AffectedHosts[2]; down.Hostname != "" || down.OperatingSystem != nil {
+		t.Errorf("down host = %+v, want it left alone", down)
+	}
+
+	flushed, err := enrich.Flush()
+	if err != nil || len(flushed) != 1 {
+		t.Fatalf("flushed %d issues, %v", len(flushed), err)
+	}
+	checkIssues(t, &PrismDataStructs.Prism{Issues: []PrismDataStructs.Issue{*flushed[0]}}, []wantIssue{{
+		name:   "Open Ports",
+		rating: PrismDataStructs.RiskInfo,
+		hosts:  []string{"web01.example.com 10.0.0.1:22/tcp", "web01.example.com 10.0.0.1:443/tcp"},
+	}})
+	checkValid(t, "open ports", &PrismDataStructs.Prism{Version: PrismDataStructs.CurrentVersion, Issues: []PrismDataStructs.Issue{*flushed[0]}})
+	if service := flushed[0].AffectedHosts[1].Service; service == nil || *service != "ssl/http" {
+		t.Errorf("open port service = %v, want ssl/http", service)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestRemoveHosts(t *testing.T) {
+	port := func(port int) PrismDataStructs.AffectedHost {
+		return PrismDataStructs.AffectedHost{Ip: "10.0.0.1", Port: PrismDataStructs.NewPort(port)}
+	}
+	service := "https"
+	withService := port(443)
+	withService.Service = &service
+
+	// Two entries share a key, differing only in the service, and one host
+	// has no key at all
+	hosts := []PrismDataStructs.AffectedHost{port(443), {Hostname: "www.example.com"}, withService, {}, port(8443)}
+
+	tests := []struct {
+		remove string
+		want   []PrismDataStructs.AffectedHost
+		keep   bool
+	}{
+		{"10.0.0.2", hosts, true},
+		{"10.0.0.1:8443", hosts[:4], true},
+		{"10.0.0.1", []PrismDataStructs.AffectedHost{{Hostname: "www.example.com"}, {}}, true},
+		{"www.example.com", []PrismDataStructs.AffectedHost{port(443), withService, {}, port(8443)}, true},
+	}
+
+	for _, test := range tests {
+		key, err := PrismDataStructs.ParseHostKey(test.remove)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issue := PrismDataStructs.Issue{Name: "Test", AffectedHosts: append([]PrismDataStructs.AffectedHost(nil), hosts...)}
+		if keep := removeHosts(newLogger("test"), &issue, []PrismDataStructs.HostKey{key}); keep != test.keep {
+			t.Errorf("%s: keep = %v, want %v", test.remove, keep, test.keep)
+		}
+		if !reflect.DeepEqual(issue.AffectedHosts, test.want) {
+			t.Errorf("%s: hosts = %+v, want %+v", test.remove, issue.AffectedHosts, test.want)
+		}
+	}
+
+	issue := PrismDataStructs.Issue{Name: "Test", AffectedHosts: []PrismDataStructs.AffectedHost{port(443), withService}}
+	if removeHosts(newLogger("test"), &issue, []PrismDataStructs.HostKey{PrismDataStructs.NewHostKey("10.0.0.1", "", 0, "")}) {
+		t.Error("issue left without hosts should be removed")
+	}
+}
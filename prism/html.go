@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlTable renders rows as the bordered, full width table Prism's editor
+// produces. Cells are escaped, with newlines kept as line breaks, so scanner
+// output such as a curl command or an HTTP request shows as written.
+func htmlTable(rows ...[]string) string {
+	var b strings.Builder
+	b.WriteString("<table style='border-collapse: collapse; width: 100%;' border='1'><tbody>")
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&b, "<td style='width: %s%%;'>%s</td>", cellWidth(len(row)), htmlLines(cell))
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table>")
+	return b.String()
+}
+
+// htmlColumn renders values as a single column table
+func htmlColumn(values ...string) string {
+	rows := make([][]string, len(values))
+	for i, value := range values {
+		rows[i] = []string{value}
+	}
+	return htmlTable(rows...)
+}
+
+// htmlSection renders a labelled block of technical details, spaced from
+// the block before it
+func htmlSection(label, body string) string {
+	return "<p>&nbsp;</p><p>" + html.EscapeString(label) + "</p>" + body
+}
+
+// blockTags are the elements htmlBlock leaves as they are
+var blockTags = []string{"<p", "<ul", "<ol", "<table", "<div", "<h", "<pre", "<blockquote"}
+
+// htmlBlock wraps an HTML fragment from a scanner in a paragraph unless it
+// already starts with a block element
+func htmlBlock(fragment string) string {
+	fragment = strings.TrimSpace(fragment)
+	if fragment == "" {
+		return ""
+	}
+	lower := strings.ToLower(fragment)
+	for _, tag := range blockTags {
+		if strings.HasPrefix(lower, tag) {
+			return fragment
+		}
+	}
+	return "<p>" + fragment + "</p>"
+}
+
+// htmlLines escapes text, keeping its newlines as line breaks
+func htmlLines(text string) string {
+	return strings.ReplaceAll(html.EscapeString(strings.ReplaceAll(text, "\r\n", "\n")), "\n", "<br />")
+}
+
+// cellWidth splits the table width the editor uses between columns
+func cellWidth(columns int) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", 98.5288/float64(columns)), "0"), ".")
+}
+
+// htmlParagraphs renders plain text as paragraphs, one per blank line
+// separated block, with single newlines kept as line breaks
+func htmlParagraphs(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(strings.TrimSpace(line))
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br />") + "</p>")
+	}
+	return b.String()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// importer converts one scanner's output to a Prism. Each importer is a
+// prism import subcommand and an input format for prism pipeline. Importing
+// is not streamed: results are grouped into issues, and an issue is only
+// complete once the whole report has been read, so load returns every issue
+// at once.
+type importer struct {
+	name       string
+	summary    string
+	inputUsage string
+	load       func(r io.Reader) (*PrismDataStructs.Prism, error)
+
+	// clean applies the prism fix clean-ups to every imported issue, for
+	// scanners whose wording needs them
+	clean bool
+}
+
+var importers = []importer{
+	{name: "burp", summary: "Import a Burp Suite issues XML export", inputUsage: "Burp issues XML export", load: importBurp},
+	{name: "grype", summary: "Import a Grype JSON report as one issue per vulnerable package", inputUsage: "Grype JSON report (grype -o json)", load: importGrype},
+	{name: "nessus", summary: "Import a .nessus v2 XML file", inputUsage: ".nessus file", load: importNessus, clean: true},
+	{name: "nexpose", summary: "Import a Nexpose XML Export 2.0 report", inputUsage: "Nexpose XML Export 2.0 report", load: importNexpose, clean: true},
+	{name: "nuclei", summary: "Import nuclei JSON lines output", inputUsage: "nuclei JSON lines output (nuclei -json)", load: importNuclei},
+	{name: "openvas", summary: "Import a Greenbone or OpenVAS XML report", inputUsage: "Greenbone or OpenVAS XML report", load: importOpenvas, clean: true},
+	{name: "qualys", summary: "Import a Qualys VM scan report XML", inputUsage: "Qualys scan report XML", load: importQualys, clean: true},
+	{name: "sarif", summary: "Import a SARIF log from a SAST or IaC scanner", inputUsage: "SARIF 2.1.0 log", load: importSarif},
+	{name: "sslscan", summary: "Import sslscan XML output as consolidated TLS issues", inputUsage: "sslscan XML output (sslscan --xml)", load: importSslscan},
+	{name: "testssl", summary: "Import testssl.sh JSON output as consolidated TLS issues", inputUsage: "testssl.sh JSON output (--json or --jsonfile-pretty)", load: importTestssl},
+	{name: "trivy", summary: "Import a Trivy JSON report as one issue per vulnerable package", inputUsage: "Trivy JSON report (trivy --format json)", load: importTrivy},
+	{name: "zap", summary: "Import an OWASP ZAP JSON or XML report", inputUsage: "ZAP traditional JSON or XML report", load: importZap},
+}
+
+func findImporter(name string) (importer, error) {
+	for _, imp := range importers {
+		if imp.name == name {
+			return imp, nil
+		}
+	}
+	return importer{}, fmt.Errorf("unknown input format %q", name)
+}
+
+// importCommands lists the prism import subcommands
+func importCommands() []command {
+	cmds := make([]command, len(importers))
+	for i, imp := range importers {
+		imp := imp
+		cmds[i] = command{name: imp.name, summary: imp.summary, run: func(args []string) int {
+			return runImport(imp, args)
+		}}
+	}
+	return cmds
+}
+
+func runImport(imp importer, args []string) int {
+	flags := flag.NewFlagSet("import "+imp.name, flag.ExitOnError)
+	files := addIOFlags(flags, imp.inputUsage, "Prism file to write")
+	var fixCVSS, raw *bool
+	if imp.clean {
+		fixCVSS = flags.Bool("cvss", false, "Map the CVSS vector with the Severity of the issue")
+		raw = flags.Bool("raw", false, "Skip the prism fix clean-ups")
+	}
+	flags.Parse(args)
+
+	log := newLogger("import " + imp.name)
+
+	in, err := files.openInput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer in.Close()
+
+	prism, err := imp.importFrom(log, in)
+	if err != nil {
+		return log.Fail(err)
+	}
+	log.Infof("Imported %d issues", len(prism.Issues))
+
+	if imp.clean && !*raw {
+		fix := &fixTransform{log: log, fixCVSS: *fixCVSS, imported: true}
+		for i := range prism.Issues {
+			fix.Apply(&prism.Issues[i])
+		}
+	}
+
+	out, err := files.createOutput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	if err = prism.Save(out, PrismDataStructs.DefaultSaveOptions); err != nil {
+		return log.Fail(err)
+	}
+	return log.ExitCode()
+}
+
+// importFrom runs the importer, keeping each issue's CVE list within what
+// Prism accepts and giving every issue the finding, summary and
+// recommendation Prism requires
+func (imp importer) importFrom(log *logger, r io.Reader) (*PrismDataStructs.Prism, error) {
+	prism, err := imp.load(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := range prism.Issues {
+		truncateCves(log, &prism.Issues[i])
+		completeIssue(&prism.Issues[i])
+	}
+	return prism, nil
+}
+
+// completeIssue fills in the finding, summary and recommendation of an issue
+// the scanner gave none for. The summary is the first paragraph of the
+// finding, and the recommendation points to the references when there are
+// any.
+func completeIssue(issue *PrismDataStructs.Issue) {
+	if strings.TrimSpace(issue.Finding) == "" {
+		issue.Finding = htmlParagraphs(issue.Name + " was identified.")
+	}
+	if issue.Summary == nil {
+		summary := leadParagraph(issue.Finding)
+		if summary == "" {
+			summary = htmlParagraphs(issue.Name + " was identified.")
+		}
+		issue.Summary = &summary
+	}
+
+	if issue.Recommendation == nil {
+		recommendation := "<p>It is recommended that the issue is reviewed and remediated.</p>"
+		if len(issue.References) > 0 {
+			recommendation = "<p>It is recommended that the issue is remediated following the guidance in the references.</p>"
+		}
+		issue.Recommendation = &recommendation
+	}
+}
+
+// leadParagraph returns the first paragraph of an HTML fragment, or "" when
+// it does not start with one
+func leadParagraph(fragment string) string {
+	block := htmlBlock(fragment)
+	end := strings.Index(block, "</p>")
+	if !strings.HasPrefix(block, "<p>") || end < 0 {
+		return ""
+	}
+	return block[:end+len("</p>")]
+}
+
+// Ports implied by the scheme of a URL
+var defaultPorts = map[string]int{
+	"http":  80,
+	"https": 443,
+}
+
+// urlAffectedHost builds the affected host for a URL or host:port a scanner
+// reported, taking the port from the URL or the default port of its scheme.
+// ip is the address the scanner resolved, if it gave one.
+func urlAffectedHost(rawURL, ip string) PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+
+	host := rawURL
+	if !strings.Contains(host, "://") {
+		host = "tcp://" + host
+	}
+
+	hostname, port := rawURL, 0
+	if u, err := url.Parse(host); err == nil && u.Hostname() != "" {
+		hostname = u.Hostname()
+		port, _ = strconv.Atoi(u.Port())
+		if port == 0 {
+			port = defaultPorts[u.Scheme]
+		}
+	}
+
+	// A URL of an address has no host name, and a host name is not put in
+	// place of an address the scanner did not resolve
+	affectedHost.Ip = ip
+	if net.ParseIP(hostname) == nil {
+		affectedHost.Hostname = hostname
+	} else if ip == "" {
+		affectedHost.Ip = hostname
+	}
+
+	if port != 0 {
+		protocol := "tcp"
+		affectedHost.Port = PrismDataStructs.NewPort(port)
+		affectedHost.Protocol = &protocol
+	}
+	return affectedHost
+}
+
+// issueBuilder groups an importer's results into issues by key. Each issue
+// keeps its own host set and technical details until prism is called, so a
+// result costs the same however many the issue already has.
+type issueBuilder struct {
+	issues  []PrismDataStructs.Issue
+	index   map[string]int
+	hosts   []*PrismDataStructs.HostSet
+	details []*strings.Builder
+}
+
+func newIssueBuilder() *issueBuilder {
+	return &issueBuilder{index: make(map[string]int)}
+}
+
+// issue returns the issue for key, or nil when it has not been added. The
+// pointer is only valid until the next issue is added.
+func (b *issueBuilder) issue(key string) *PrismDataStructs.Issue {
+	index, ok := b.index[key]
+	if !ok {
+		return nil
+	}
+	return &b.issues[index]
+}
+
+// add starts the issue for key. Its affected hosts and technical details are
+// kept by the builder from now on, so add to them with addHost and
+// addDetails.
+func (b *issueBuilder) add(key string, issue PrismDataStructs.Issue) {
+	details := &strings.Builder{}
+	details.WriteString(issue.TechnicalDetails)
+
+	b.index[key] = len(b.issues)
+	b.hosts = append(b.hosts, PrismDataStructs.NewHostSet(issue.AffectedHosts...))
+	b.details = append(b.details, details)
+
+	issue.AffectedHosts, issue.TechnicalDetails = nil, ""
+	b.issues = append(b.issues, issue)
+}
+
+// addHost adds a host to the issue for key unless it already has it
+func (b *issueBuilder) addHost(key string, host PrismDataStructs.AffectedHost) {
+	b.hosts[b.index[key]].Add(host)
+}
+
+// addDetails appends to the technical details of the issue for key
+func (b *issueBuilder) addDetails(key, details string) {
+	b.details[b.index[key]].WriteString(details)
+}
+
+// hostKeys returns the keys of the hosts of the issue for key, in the order
+// they were added
+func (b *issueBuilder) hostKeys(key string) []PrismDataStructs.HostKey {
+	return b.hosts[b.index[key]].Keys()
+}
+
+// prism builds the issues in the order they were added
+func (b *issueBuilder) prism() *PrismDataStructs.Prism {
+	prism := &PrismDataStructs.Prism{Version: PrismDataStructs.CurrentVersion}
+	for i, issue := range b.issues {
+		issue.AffectedHosts = b.hosts[i].Hosts()
+		issue.TechnicalDetails = b.details[i].String()
+		prism.Issues = append(prism.Issues, issue)
+	}
+	return prism
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// burpIssue is one issue from Burp's "Report selected issues" XML export
+type burpIssue struct {
+	Type string `xml:"type"`
+	Name string `xml:"name"`
+	Host struct {
+		IP  string `xml:"ip,attr"`
+		URL string `xml:",chardata"`
+	} `xml:"host"`
+	Location                     string `xml:"location"`
+	Severity                     string `xml:"severity"`
+	Confidence                   string `xml:"confidence"`
+	IssueBackground              string `xml:"issueBackground"`
+	RemediationBackground        string `xml:"remediationBackground"`
+	References                   string `xml:"references"`
+	VulnerabilityClassifications string `xml:"vulnerabilityClassifications"`
+	IssueDetail                  string `xml:"issueDetail"`
+	RemediationDetail            string `xml:"remediationDetail"`
+	RequestResponses             []struct {
+		Request  burpMessage `xml:"request"`
+		Response burpMessage `xml:"response"`
+	} `xml:"requestresponse"`
+}
+
+// burpMessage is a request or response, base64 encoded unless the export
+// was made without that option
+type burpMessage struct {
+	Base64 bool   `xml:"base64,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// burpEvidenceLimit caps each request and response shown in the technical
+// details, as responses often carry a whole page
+const burpEvidenceLimit = 4096
+
+var (
+	burpRatings = map[string]PrismDataStructs.RiskRating{
+		"High":        PrismDataStructs.RiskHigh,
+		"Medium":      PrismDataStructs.RiskMedium,
+		"Low":         PrismDataStructs.RiskLow,
+		"Information": PrismDataStructs.RiskInfo,
+	}
+
+	hrefRegex = regexp.MustCompile(`href="([^"]+)"`)
+)
+
+// importBurp converts a Burp issues export to one issue per issue type,
+// with one affected host per host and port and the evidence of every
+// location in the technical details
+func importBurp(r io.Reader) (*PrismDataStructs.Prism, error) {
+	issues := newIssueBuilder()
+	today := time.Now().Format("2006-01-02")
+
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "issue" {
+			continue
+		}
+
+		var result burpIssue
+		if err = dec.DecodeElement(&result, &start); err != nil {
+			return nil, err
+		}
+
+		affectedHost := urlAffectedHost(strings.TrimSpace(result.Host.URL), result.Host.IP)
+		if u, err := url.Parse(strings.TrimSpace(result.Host.URL)); err == nil && u.Scheme != "" {
+			service := u.Scheme
+			affectedHost.Service = &service
+		}
+
+		// Extension generated issues share a type, so the name is part of the key
+		key := result.Type + "\x00" + result.Name
+		if issues.issue(key) != nil {
+			issues.addHost(key, affectedHost)
+			issues.addDetails(key, burpTechnicalDetails(result))
+			continue
+		}
+
+		var issue PrismDataStructs.Issue
+		issue.Name = result.Name
+		issue.Finding = htmlBlock(result.IssueBackground)
+		issue.ConfirmedAt = today
+		issue.Status = "open"
+
+		rating, ok := burpRatings[result.Severity]
+		if !ok {
+			rating = PrismDataStructs.RiskInfo
+		}
+		issue.OriginalRiskRating = rating
+
+		if result.RemediationBackground != "" {
+			recommendation := htmlBlock(result.RemediationBackground)
+			issue.Recommendation = &recommendation
+		}
+
+		for _, match := range hrefRegex.FindAllStringSubmatch(result.References+result.VulnerabilityClassifications, -1) {
+			issue.References = append(issue.References, match[1])
+		}
+
+		issue.AffectedHosts = []PrismDataStructs.AffectedHost{affectedHost}
+		issue.TechnicalDetails = burpTechnicalDetails(result)
+
+		issues.add(key, issue)
+	}
+	return issues.prism(), nil
+}
+
+// burpTechnicalDetails shows one location of an issue: Burp's detail for
+// it, its confidence and each request and response
+func burpTechnicalDetails(result burpIssue) string {
+	location := strings.TrimSpace(result.Host.URL) + strings.TrimSpace(result.Location)
+	td := htmlSection("URL: "+location, "<p>Confidence: "+result.Confidence+"</p>")
+	td += htmlBlock(result.IssueDetail) + htmlBlock(result.RemediationDetail)
+
+	for _, rr := range result.RequestResponses {
+		var rows [][]string
+		if request := rr.Request.text(); request != "" {
+			rows = append(rows, []string{"Request", request})
+		}
+		if response := rr.Response.text(); response != "" {
+			rows = append(rows, []string{"Response", response})
+		}
+		if rows != nil {
+			td += htmlTable(rows...)
+		}
+	}
+	return td
+}
+
+// text decodes the message and cuts it to burpEvidenceLimit
+func (m burpMessage) text() string {
+	text := m.Value
+	if m.Base64 {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return ""
+		}
+		text = string(decoded)
+	}
+	text = strings.TrimSpace(text)
+
+	if len(text) > burpEvidenceLimit {
+		cut := burpEvidenceLimit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "\n[truncated]"
+	}
+	return text
+}
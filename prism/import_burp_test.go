@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportBurp(t *testing.T) {
+	prism := importFixture(t, "burp", "burp.xml")
+	checkIssues(t, prism, []wantIssue{
+		{
+			name:       "Cross-site scripting (reflected)",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"shop.example.com 10.0.0.10:443/tcp", "admin.example.com 10.0.0.11:8080/tcp"},
+			references: []string{"https://portswigger.net/web-security/cross-site-scripting", "https://cwe.mitre.org/data/definitions/79.html"},
+		},
+		{
+			name:   "Frameable response (potential Clickjacking)",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{"shop.example.com 10.0.0.10:443/tcp"},
+		},
+	})
+
+	xss := prism.Issues[0]
+	if service := xss.AffectedHosts[1].Service; service == nil || *service != "http" {
+		t.Errorf("service = %v, want the URL scheme", service)
+	}
+	for _, want := range []string{
+		"URL: https://shop.example.com/search [q parameter]",
+		"URL: http://admin.example.com:8080/login [next parameter]",
+		"GET /search?q=test HTTP/1.1<br />Host: shop.example.com",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(xss.TechnicalDetails, want) {
+			t.Errorf("technical details missing %q", want)
+		}
+	}
+	if finding := prism.Issues[1].Finding; !strings.HasPrefix(finding, "<p>If a page fails") {
+		t.Errorf("finding = %q, want plain text wrapped in a paragraph", finding)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// grypeReport is the part of Grype's JSON report (-o json) the importer reads
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			grypeVulnerability
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+
+			// CISA's Known Exploited Vulnerabilities entries, which newer
+			// releases of Grype include
+			KnownExploited []json.RawMessage `json:"knownExploited"`
+		} `json:"vulnerability"`
+		RelatedVulnerabilities []grypeVulnerability `json:"relatedVulnerabilities"`
+		Artifact               struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		// Target is the image's details, or the path of a directory scan
+		Target json.RawMessage `json:"target"`
+	} `json:"source"`
+	Distro struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"distro"`
+}
+
+type grypeVulnerability struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	URLs        []string `json:"urls"`
+	CVSS        []struct {
+		Version string `json:"version"`
+		Vector  string `json:"vector"`
+		Metrics struct {
+			BaseScore float64 `json:"baseScore"`
+		} `json:"metrics"`
+	} `json:"cvss"`
+}
+
+// importGrype converts a Grype image or directory scan to one issue per
+// vulnerable package, with the scanned image as the affected host
+func importGrype(r io.Reader) (*PrismDataStructs.Prism, error) {
+	var report grypeReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	var target string
+	if json.Unmarshal(report.Source.Target, &target) != nil {
+		var image struct {
+			UserInput string `json:"userInput"`
+		}
+		if err := json.Unmarshal(report.Source.Target, &image); err != nil {
+			return nil, err
+		}
+		target = image.UserInput
+	}
+
+	host := imageAffectedHost(target, report.Distro.Name+" "+report.Distro.Version)
+	findings := newPackageFindings()
+
+	for _, match := range report.Matches {
+		v := match.Vulnerability
+
+		// Grype's Negligible and Unknown severities are not ratings
+		rating, err := PrismDataStructs.ParseRiskRating(v.Severity)
+		if err != nil {
+			rating = PrismDataStructs.RiskInfo
+		}
+
+		vuln := packageVuln{
+			id:          v.ID,
+			description: v.Description,
+			rating:      rating,
+			installed:   match.Artifact.Version,
+			fixed:       strings.Join(v.Fix.Versions, ", "),
+			exploited:   len(v.KnownExploited) > 0,
+		}
+		if len(v.URLs) > 0 {
+			vuln.url = v.URLs[0]
+		}
+		vuln.setVector(v.grypeVulnerability)
+
+		// Matches against a distro or GitHub advisory carry the NVD record,
+		// with the CVE, description and score, as a related vulnerability
+		for _, related := range match.RelatedVulnerabilities {
+			if related.ID != v.ID {
+				vuln.aliases = append(vuln.aliases, related.ID)
+			}
+			vuln.description = firstNonEmpty(vuln.description, related.Description)
+			vuln.setVector(related)
+		}
+
+		findings.add(host, match.Artifact.Name, vuln)
+	}
+	return findings.prism(), nil
+}
+
+// setVector keeps the vulnerability's highest scoring CVSS vector, preferring
+// version 3 to version 2
+func (v *packageVuln) setVector(grype grypeVulnerability) {
+	for _, cvss := range grype.CVSS {
+		v3 := strings.HasPrefix(cvss.Version, "3")
+		current := strings.HasPrefix(v.vector, "CVSS:3")
+		if cvss.Vector == "" || (current && !v3) {
+			continue
+		}
+		if v.vector == "" || (v3 && !current) || cvss.Metrics.BaseScore > v.score {
+			v.vector, v.score = cvss.Vector, cvss.Metrics.BaseScore
+		}
+	}
+}
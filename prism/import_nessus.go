@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// nessusHost is a ReportHost from a .nessus v2 file
+type nessusHost struct {
+	Name       string `xml:"name,attr"`
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"HostProperties>tag"`
+	Items []nessusItem `xml:"ReportItem"`
+}
+
+// nessusItem is a ReportItem, one plugin result on one port of a host
+type nessusItem struct {
+	Port             int      `xml:"port,attr"`
+	Service          string   `xml:"svc_name,attr"`
+	Protocol         string   `xml:"protocol,attr"`
+	Severity         int      `xml:"severity,attr"`
+	PluginID         int64    `xml:"pluginID,attr"`
+	PluginName       string   `xml:"pluginName,attr"`
+	Description      string   `xml:"description"`
+	Synopsis         string   `xml:"synopsis"`
+	Solution         string   `xml:"solution"`
+	SeeAlso          string   `xml:"see_also"`
+	PluginOutput     string   `xml:"plugin_output"`
+	Cves             []string `xml:"cve"`
+	Cvss3Vector      string   `xml:"cvss3_vector"`
+	CvssVector       string   `xml:"cvss_vector"`
+	ExploitAvailable string   `xml:"exploit_available"`
+}
+
+// Nessus severities 0 to 4
+var nessusRatings = []PrismDataStructs.RiskRating{
+	PrismDataStructs.RiskInfo,
+	PrismDataStructs.RiskLow,
+	PrismDataStructs.RiskMedium,
+	PrismDataStructs.RiskHigh,
+	PrismDataStructs.RiskCritical,
+}
+
+// Layout of the HOST_START and HOST_END host properties
+const nessusTimeLayout = "Mon Jan _2 15:04:05 2006"
+
+// importNessus converts a .nessus v2 file to one issue per plugin, streaming
+// it a host at a time
+func importNessus(r io.Reader) (*PrismDataStructs.Prism, error) {
+	issues := newIssueBuilder()
+
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "ReportHost" {
+			continue
+		}
+
+		var host nessusHost
+		if err = dec.DecodeElement(&host, &start); err != nil {
+			return nil, err
+		}
+
+		for _, item := range host.Items {
+			affectedHost := host.affectedHost(item)
+
+			key := strconv.FormatInt(item.PluginID, 10)
+			if issue := issues.issue(key); issue != nil {
+				issues.addHost(key, affectedHost)
+				issues.addDetails(key, nessusTechnicalDetails(affectedHost, item))
+				addCves(issue, item.Cves)
+				continue
+			}
+			issues.add(key, nessusIssue(host, item, affectedHost))
+		}
+	}
+	return issues.prism(), nil
+}
+
+// nessusIssue creates the issue for the first result of a plugin
+func nessusIssue(host nessusHost, item nessusItem, affectedHost PrismDataStructs.AffectedHost) PrismDataStructs.Issue {
+	var issue PrismDataStructs.Issue
+	issue.Name = item.PluginName
+	issue.NessusId = PrismDataStructs.NewNessusId(item.PluginID)
+	issue.Finding = htmlParagraphs(item.Description)
+	issue.Status = "open"
+	issue.ConfirmedAt = host.confirmedAt()
+
+	issue.OriginalRiskRating = PrismDataStructs.RiskInfo
+	if item.Severity >= 0 && item.Severity < len(nessusRatings) {
+		issue.OriginalRiskRating = nessusRatings[item.Severity]
+	}
+
+	if item.Synopsis != "" {
+		summary := htmlParagraphs(item.Synopsis)
+		issue.Summary = &summary
+	}
+	if item.Solution != "" && item.Solution != "n/a" {
+		recommendation := htmlParagraphs(item.Solution)
+		issue.Recommendation = &recommendation
+	}
+
+	for _, reference := range strings.Fields(item.SeeAlso) {
+		issue.References = append(issue.References, reference)
+	}
+
+	// Prefer the CVSS v3 vector, which fix re-scores as v3.1
+	vector := item.Cvss3Vector
+	if vector == "" {
+		vector = item.CvssVector
+	}
+	if vector != "" {
+		issue.CvssVector = &vector
+	}
+
+	if item.ExploitAvailable != "" {
+		exploitAvailable := item.ExploitAvailable == "true"
+		issue.ExploitAvailable = &exploitAvailable
+	}
+
+	addCves(&issue, item.Cves)
+	issue.AffectedHosts = []PrismDataStructs.AffectedHost{affectedHost}
+	issue.TechnicalDetails = nessusTechnicalDetails(affectedHost, item)
+	return issue
+}
+
+// nessusTechnicalDetails shows the plugin output for one host
+func nessusTechnicalDetails(host PrismDataStructs.AffectedHost, item nessusItem) string {
+	if strings.TrimSpace(item.PluginOutput) == "" {
+		return ""
+	}
+	return htmlSection("Host: "+host.Key().String(), htmlParagraphs(item.PluginOutput))
+}
+
+// addCves adds the CVEs an issue does not have yet, keeping them sorted by
+// year and number
+func addCves(issue *PrismDataStructs.Issue, cves []string) {
+	if len(cves) == 0 {
+		return
+	}
+
+	var all []string
+	if issue.Cves != nil {
+		all = *issue.Cves
+	}
+	seen := make(map[string]bool)
+	for _, cve := range all {
+		seen[cve] = true
+	}
+	for _, cve := range cves {
+		cve = strings.ToUpper(strings.TrimSpace(cve))
+		if cve != "" && !seen[cve] {
+			seen[cve] = true
+			all = append(all, cve)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return cveLess(all[i], all[j])
+	})
+	issue.Cves = &all
+}
+
+// cveLess orders CVE IDs by year then number, so CVE-2021-9999 comes before
+// CVE-2021-10000. Anything else sorts after them as text.
+func cveLess(a, b string) bool {
+	aYear, aNumber, aOK := parseCve(a)
+	bYear, bNumber, bOK := parseCve(b)
+	switch {
+	case aOK && bOK && aYear != bYear:
+		return aYear < bYear
+	case aOK && bOK && aNumber != bNumber:
+		return aNumber < bNumber
+	case aOK != bOK:
+		return aOK
+	}
+	return a < b
+}
+
+// parseCve splits a CVE ID such as CVE-2021-44228 into its year and number
+func parseCve(cve string) (year, number int, ok bool) {
+	parts := strings.Split(cve, "-")
+	if len(parts) != 3 || parts[0] != "CVE" {
+		return 0, 0, false
+	}
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	number, err = strconv.Atoi(parts[2])
+	return year, number, err == nil
+}
+
+func (h nessusHost) property(name string) string {
+	for _, property := range h.Properties {
+		if property.Name == name {
+			return strings.TrimSpace(property.Value)
+		}
+	}
+	return ""
+}
+
+// affectedHost builds the host an item was found on. The report host name
+// is the target as scanned, which may be an address or a hostname.
+func (h nessusHost) affectedHost(item nessusItem) PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+
+	affectedHost.Ip = h.property("host-ip")
+	affectedHost.Hostname = h.property("host-fqdn")
+	if _, err := netip.ParseAddr(h.Name); err == nil {
+		if affectedHost.Ip == "" {
+			affectedHost.Ip = h.Name
+		}
+	} else if affectedHost.Hostname == "" {
+		affectedHost.Hostname = h.Name
+	}
+	if affectedHost.Ip == "" {
+		affectedHost.Ip = affectedHost.Hostname
+	}
+
+	// Nessus lists every operating system it could not rule out, most
+	// likely first
+	if operatingSystem := h.property("operating-system"); operatingSystem != "" {
+		operatingSystem = strings.Split(operatingSystem, "\n")[0]
+		affectedHost.OperatingSystem = &operatingSystem
+	}
+
+	var cpes []string
+	for _, property := range h.Properties {
+		if property.Name == "cpe" || strings.HasPrefix(property.Name, "cpe-") {
+			cpes = append(cpes, strings.TrimSpace(property.Value))
+		}
+	}
+	if cpes != nil {
+		affectedHost.Cpes = &cpes
+	}
+
+	// Port 0 is Nessus's "general" pseudo-service, a finding about the host
+	if item.Port != 0 {
+		protocol := item.Protocol
+		affectedHost.Port = PrismDataStructs.NewPort(item.Port)
+		affectedHost.Protocol = &protocol
+		if item.Service != "" {
+			service := strings.TrimSuffix(item.Service, "?")
+			affectedHost.Service = &service
+		}
+	}
+	return affectedHost
+}
+
+// confirmedAt is the date the scan of the host finished, or today
+func (h nessusHost) confirmedAt() string {
+	if end, err := time.Parse(nessusTimeLayout, h.property("HOST_END")); err == nil {
+		return end.Format("2006-01-02")
+	}
+	return time.Now().Format("2006-01-02")
+}
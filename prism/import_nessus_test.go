@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportNessus(t *testing.T) {
+	prism := importFixture(t, "nessus", "scan.nessus")
+	checkIssues(t, prism, []wantIssue{
+		{
+			name:   "Nessus Scan Information",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{"web01.example.com 10.0.0.1"},
+		},
+		{
+			name:       "OpenSSL 1.1.1 < 1.1.1n Vulnerability",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"web01.example.com 10.0.0.1:443/tcp", "10.0.0.2:8443/tcp"},
+			cves:       []string{"CVE-2022-0778"},
+			cvss:       "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+			references: []string{"https://www.openssl.org/news/secadv/20220315.txt", "https://www.nessus.org/u?7e2b4d3e"},
+		},
+	})
+
+	issue := prism.Issues[1]
+	if issue.NessusId.String() != "158974" || issue.ExploitAvailable == nil || !*issue.ExploitAvailable || issue.ConfirmedAt != "2024-03-12" {
+		t.Errorf("nessus id %s, exploit %v, confirmed %q", issue.NessusId, issue.ExploitAvailable, issue.ConfirmedAt)
+	}
+
+	host := issue.AffectedHosts[0]
+	if host.Service == nil || *host.Service != "www" || host.OperatingSystem == nil || *host.OperatingSystem != "Linux Kernel 5.4" ||
+		host.Cpes == nil || len(*host.Cpes) != 1 {
+		t.Errorf("first host = %+v", host)
+	}
+	if service := issue.AffectedHosts[1].Service; service == nil || *service != "pcsync-https" {
+		t.Errorf("uncertain service = %v, want the trailing ? removed", service)
+	}
+}
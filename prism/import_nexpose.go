@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/xml"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// nexposeNode is a scanned host from a Nexpose XML Export 2.0 report
+type nexposeNode struct {
+	Address string   `xml:"address,attr"`
+	Names   []string `xml:"names>name"`
+	OSes    []struct {
+		Vendor  string `xml:"vendor,attr"`
+		Product string `xml:"product,attr"`
+		Version string `xml:"version,attr"`
+		Cpe     string `xml:"cpe,attr"`
+	} `xml:"fingerprints>os"`
+	Tests     []nexposeTest `xml:"tests>test"`
+	Endpoints []struct {
+		Protocol string `xml:"protocol,attr"`
+		Port     int    `xml:"port,attr"`
+		Services []struct {
+			Name  string        `xml:"name,attr"`
+			Tests []nexposeTest `xml:"tests>test"`
+		} `xml:"services>service"`
+	} `xml:"endpoints>endpoint"`
+}
+
+// nexposeTest is the result of one vulnerability check, with its evidence
+type nexposeTest struct {
+	ID       string `xml:"id,attr"`
+	Status   string `xml:"status,attr"`
+	Evidence string `xml:",innerxml"`
+}
+
+// nexposeVulnerability is a definition from the VulnerabilityDefinitions
+// that follow the nodes
+type nexposeVulnerability struct {
+	ID           string      `xml:"id,attr"`
+	Title        string      `xml:"title,attr"`
+	Severity     int         `xml:"severity,attr"`
+	CvssScore    string      `xml:"cvssScore,attr"`
+	CvssVector   string      `xml:"cvssVector,attr"`
+	CvssV3Score  string      `xml:"cvssV3Score,attr"`
+	CvssV3Vector string      `xml:"cvssV3Vector,attr"`
+	Description  nexposeText `xml:"description"`
+	Solution     nexposeText `xml:"solution"`
+	Exploits     []struct{}  `xml:"exploits>exploit"`
+	References   []struct {
+		Source string `xml:"source,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"references>reference"`
+}
+
+// nexposeText is an element of Nexpose's rich text, made of Paragraph, list
+// and URLLink elements
+type nexposeText struct {
+	Inner string `xml:",innerxml"`
+}
+
+// nexposeFinding is a vulnerable test on one host, kept until the
+// definitions have been read
+type nexposeFinding struct {
+	host PrismDataStructs.AffectedHost
+	test nexposeTest
+}
+
+// Layout of the scan times, which end in milliseconds
+const nexposeTimeLayout = "20060102T150405"
+
+// importNexpose converts a Nexpose XML Export 2.0 report to one issue per
+// vulnerability, with its Nexpose ID as the Rapid7 ID
+func importNexpose(r io.Reader) (*PrismDataStructs.Prism, error) {
+	var findings []nexposeFinding
+	vulnerabilities := make(map[string]*nexposeVulnerability)
+	confirmedAt := time.Now().Format("2006-01-02")
+
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "scan":
+			for _, attr := range start.Attr {
+				if attr.Name.Local != "endTime" || len(attr.Value) < len(nexposeTimeLayout) {
+					continue
+				}
+				if end, err := time.Parse(nexposeTimeLayout, attr.Value[:len(nexposeTimeLayout)]); err == nil {
+					confirmedAt = end.Format("2006-01-02")
+				}
+			}
+
+		case "node":
+			var node nexposeNode
+			if err = dec.DecodeElement(&node, &start); err != nil {
+				return nil, err
+			}
+			findings = append(findings, node.findings()...)
+
+		case "vulnerability":
+			var vulnerability nexposeVulnerability
+			if err = dec.DecodeElement(&vulnerability, &start); err != nil {
+				return nil, err
+			}
+			vulnerabilities[strings.ToLower(vulnerability.ID)] = &vulnerability
+		}
+	}
+
+	issues := newIssueBuilder()
+	for _, finding := range findings {
+		id := strings.ToLower(finding.test.ID)
+		vulnerability, ok := vulnerabilities[id]
+		if !ok {
+			continue
+		}
+
+		if issues.issue(id) == nil {
+			issues.add(id, nexposeIssue(vulnerability, confirmedAt))
+		}
+		issues.addHost(id, finding.host)
+		issues.addDetails(id, nexposeTechnicalDetails(finding))
+	}
+	return issues.prism(), nil
+}
+
+// nexposeIssue creates the issue for a vulnerability definition
+func nexposeIssue(vulnerability *nexposeVulnerability, confirmedAt string) PrismDataStructs.Issue {
+	var issue PrismDataStructs.Issue
+	issue.Name = vulnerability.Title
+	rapid7Id := vulnerability.ID
+	issue.Rapid7Id = &rapid7Id
+	issue.Finding = nexposeHTML(vulnerability.Description.Inner)
+	issue.Status = "open"
+	issue.ConfirmedAt = confirmedAt
+
+	if solution := nexposeHTML(vulnerability.Solution.Inner); solution != "" {
+		issue.Recommendation = &solution
+	}
+
+	// Rate by the CVSS score, v3 when Nexpose has one, falling back to
+	// Nexpose's own 1 to 10 severity on the same scale
+	issue.OriginalRiskRating = PrismDataStructs.RiskRatingFromCVSS(float64(vulnerability.Severity))
+	for _, score := range []string{vulnerability.CvssV3Score, vulnerability.CvssScore} {
+		if cvss, err := strconv.ParseFloat(score, 64); err == nil {
+			issue.OriginalRiskRating = PrismDataStructs.RiskRatingFromCVSS(cvss)
+			break
+		}
+	}
+
+	vector := vulnerability.CvssV3Vector
+	if vector == "" {
+		vector = vulnerability.CvssVector
+	}
+	if vector != "" {
+		issue.CvssVector = &vector
+	}
+
+	if len(vulnerability.Exploits) > 0 {
+		exploitAvailable := true
+		issue.ExploitAvailable = &exploitAvailable
+	}
+
+	var cves []string
+	for _, reference := range vulnerability.References {
+		value := strings.TrimSpace(reference.Value)
+		switch strings.ToUpper(reference.Source) {
+		case "CVE":
+			cves = append(cves, value)
+		case "URL":
+			issue.References = append(issue.References, value)
+		}
+	}
+	addCves(&issue, cves)
+	return issue
+}
+
+// nexposeTechnicalDetails shows the evidence of a test on one host
+func nexposeTechnicalDetails(finding nexposeFinding) string {
+	evidence := nexposeHTML(finding.test.Evidence)
+	if evidence == "" {
+		return ""
+	}
+	return htmlSection("Host: "+finding.host.Key().String(), evidence)
+}
+
+// findings lists the vulnerable tests on the node and on each of its
+// services
+func (n nexposeNode) findings() []nexposeFinding {
+	var host PrismDataStructs.AffectedHost
+	host.Ip = n.Address
+	if len(n.Names) > 0 {
+		host.Hostname = n.Names[0]
+	}
+
+	// Fingerprints are listed most certain first
+	if len(n.OSes) > 0 {
+		os := n.OSes[0]
+		if operatingSystem := strings.Join(strings.Fields(os.Vendor+" "+os.Product+" "+os.Version), " "); operatingSystem != "" {
+			host.OperatingSystem = &operatingSystem
+		}
+		if os.Cpe != "" {
+			host.Cpes = &[]string{os.Cpe}
+		}
+	}
+
+	var findings []nexposeFinding
+	for _, test := range n.Tests {
+		if test.vulnerable() {
+			findings = append(findings, nexposeFinding{host: host, test: test})
+		}
+	}
+	for _, endpoint := range n.Endpoints {
+		for _, service := range endpoint.Services {
+			for _, test := range service.Tests {
+				if !test.vulnerable() {
+					continue
+				}
+
+				endpointHost := host
+				protocol := endpoint.Protocol
+				endpointHost.Port = PrismDataStructs.NewPort(endpoint.Port)
+				endpointHost.Protocol = &protocol
+				if service.Name != "" {
+					name := strings.ToLower(service.Name)
+					endpointHost.Service = &name
+				}
+				findings = append(findings, nexposeFinding{host: endpointHost, test: test})
+			}
+		}
+	}
+	return findings
+}
+
+// vulnerable is true for confirmed and potential vulnerabilities, such as
+// vulnerable-exploited, vulnerable-version and potential
+func (t nexposeTest) vulnerable() bool {
+	return strings.HasPrefix(t.Status, "vulnerable") || t.Status == "potential"
+}
+
+// nexposeHTML renders Nexpose's rich text as HTML. Paragraphs nest in
+// Nexpose's output, so each run of text becomes one paragraph, except
+// preformatted paragraphs such as command output, which keep their layout.
+func nexposeHTML(inner string) string {
+	var b strings.Builder
+	open := false
+	closeParagraph := func() {
+		if open {
+			b.WriteString("</p>")
+			open = false
+		}
+	}
+	openParagraph := func(items int) {
+		if !open && items == 0 {
+			b.WriteString("<p>")
+			open = true
+		}
+	}
+
+	items := 0
+	var preformat []bool
+	dec := xml.NewDecoder(strings.NewReader(inner))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "Paragraph":
+				closeParagraph()
+				pre := false
+				for _, attr := range token.Attr {
+					pre = pre || attr.Name.Local == "preformat" && attr.Value == "true"
+				}
+				if pre {
+					b.WriteString("<pre>")
+				}
+				preformat = append(preformat, pre)
+			case "UnorderedList":
+				closeParagraph()
+				b.WriteString("<ul>")
+			case "OrderedList":
+				closeParagraph()
+				b.WriteString("<ol>")
+			case "ListItem":
+				items++
+				b.WriteString("<li>")
+			case "URLLink":
+				var link, title string
+				for _, attr := range token.Attr {
+					switch attr.Name.Local {
+					case "LinkURL":
+						link = attr.Value
+					case "LinkTitle":
+						title = attr.Value
+					}
+				}
+				if title == "" {
+					title = link
+				}
+				openParagraph(items)
+				b.WriteString(`<a href="` + html.EscapeString(link) + `">` + html.EscapeString(title) + "</a> ")
+			}
+
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "Paragraph":
+				closeParagraph()
+				if len(preformat) > 0 {
+					if preformat[len(preformat)-1] {
+						b.WriteString("</pre>")
+					}
+					preformat = preformat[:len(preformat)-1]
+				}
+			case "UnorderedList":
+				b.WriteString("</ul>")
+			case "OrderedList":
+				b.WriteString("</ol>")
+			case "ListItem":
+				items--
+				b.WriteString("</li>")
+			}
+
+		case xml.CharData:
+			if len(preformat) > 0 && preformat[len(preformat)-1] {
+				b.WriteString(html.EscapeString(strings.Trim(string(token), "\r\n")))
+				continue
+			}
+			text := strings.Join(strings.Fields(string(token)), " ")
+			if text == "" {
+				continue
+			}
+			openParagraph(items)
+			b.WriteString(html.EscapeString(text) + " ")
+		}
+	}
+	closeParagraph()
+	return strings.ReplaceAll(b.String(), " </", "</")
+}
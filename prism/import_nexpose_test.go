@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportNexpose(t *testing.T) {
+	prism := importFixture(t, "nexpose", "nexpose.xml")
+	checkIssues(t, prism, []wantIssue{
+		{
+			name:   "ICMP timestamp response",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{"web01.example.com 10.0.0.1"},
+			cves:   []string{"CVE-1999-0524"},
+			cvss:   "(AV:L/AC:L/Au:N/C:N/I:N/A:N)",
+		},
+		{
+			// Rated by the v3 score rather than the 1 to 10 severity
+			name:       "TLS/SSL Birthday attacks on 64-bit block ciphers (SWEET32)",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"web01.example.com 10.0.0.1:443/tcp", "10.0.0.2:8443/tcp"},
+			cves:       []string{"CVE-2016-2183"},
+			cvss:       "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+			references: []string{"https://sweet32.info/"},
+		},
+	})
+
+	icmp, sweet32 := prism.Issues[0], prism.Issues[1]
+	if icmp.Rapid7Id == nil || *icmp.Rapid7Id != "generic-icmp-timestamp" {
+		t.Errorf("rapid7 id = %v, want the vulnerability id", icmp.Rapid7Id)
+	}
+	if icmp.ExploitAvailable == nil || !*icmp.ExploitAvailable {
+		t.Errorf("exploit available = %v, want true", icmp.ExploitAvailable)
+	}
+	if icmp.ConfirmedAt != "2024-03-12" {
+		t.Errorf("confirmed at = %q, want the scan's end date", icmp.ConfirmedAt)
+	}
+	if host := sweet32.AffectedHosts[0]; host.OperatingSystem == nil || *host.OperatingSystem != "Ubuntu Linux 20.04" {
+		t.Errorf("operating system = %v, want the first fingerprint", host.OperatingSystem)
+	}
+	if !strings.Contains(sweet32.TechnicalDetails, "TLS_RSA_WITH_3DES_EDE_CBC_SHA") {
+		t.Errorf("technical details = %q, want the test evidence", sweet32.TechnicalDetails)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// Nuclei is one result from nuclei's JSON lines output
+type Nuclei struct {
+	CurlCommand      string   `json:"curl-command"`
+	ExtractedResults []string `json:"extracted-results"`
+	Host             string   `json:"host"`
+	Info             struct {
+		Author      []string `json:"author"`
+		Description string   `json:"description"`
+		Name        string   `json:"name"`
+		Reference   []string `json:"reference"`
+		Severity    string   `json:"severity"`
+		Tags        []string `json:"tags"`
+	} `json:"info"`
+	Ip            string `json:"ip"`
+	MatchedAt     string `json:"matched-at"`
+	MatchedLine   string `json:"matched-line"`
+	MatcherStatus bool   `json:"matcher-status"`
+	Template      string `json:"template"`
+	TemplateId    string `json:"template-id"`
+	TemplatePath  string `json:"template-path"`
+	TemplateUrl   string `json:"template-url"`
+	Timestamp     string `json:"timestamp"`
+	Type          string `json:"type"`
+}
+
+// importNuclei groups nuclei results into one issue per template name
+func importNuclei(r io.Reader) (*PrismDataStructs.Prism, error) {
+	issues := newIssueBuilder()
+	today := time.Now().Format("2006-01-02")
+
+	dec := json.NewDecoder(r)
+	for {
+		var result Nuclei
+		err := dec.Decode(&result)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		affectedHost := nucleiAffectedHost(result)
+
+		// Normalize the host
+		result.Host = strings.Replace(result.Host, "http://", "", -1)
+		result.Host = strings.Replace(result.Host, "https://", "", -1)
+
+		if issues.issue(result.Info.Name) != nil {
+			issues.addHost(result.Info.Name, affectedHost)
+			issues.addDetails(result.Info.Name, nucleiTechnicalDetails(result))
+			continue
+		}
+
+		// Create a new issue
+		var issue PrismDataStructs.Issue
+		issue.Name = result.Info.Name
+		issue.Finding = result.Info.Description
+		issue.ConfirmedAt = today
+
+		// nuclei uses lower case ratings and "unknown", which Prism does not have
+		rating, err := PrismDataStructs.ParseRiskRating(result.Info.Severity)
+		if err != nil {
+			rating = PrismDataStructs.RiskInfo
+		}
+		issue.OriginalRiskRating = rating
+		issue.Status = "open"
+
+		issue.AffectedHosts = append(issue.AffectedHosts, affectedHost)
+		issue.TechnicalDetails = nucleiTechnicalDetails(result)
+
+		var references []string
+		if result.Info.Reference != nil {
+			references = append(references, result.Info.Reference...)
+		}
+
+		// The template URL lets other tools match this issue to the nuclei template
+		if result.TemplateUrl != "" {
+			references = append(references, result.TemplateUrl)
+		}
+		issue.References = references
+
+		issues.add(issue.Name, issue)
+	}
+	return issues.prism(), nil
+}
+
+// nucleiTechnicalDetails shows the request that matched and anything nuclei
+// extracted from the response
+func nucleiTechnicalDetails(result Nuclei) string {
+	td := htmlSection("Host: "+result.Host, htmlColumn(result.CurlCommand))
+	if len(result.ExtractedResults) > 0 {
+		td += htmlSection("Extracted Results:", htmlColumn(result.ExtractedResults...))
+	}
+	return td
+}
+
+// nucleiAffectedHost builds the affected host for a result
+func nucleiAffectedHost(result Nuclei) PrismDataStructs.AffectedHost {
+	return urlAffectedHost(result.Host, result.Ip)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportNuclei(t *testing.T) {
+	prism := importFixture(t, "nuclei", "nuclei.jsonl")
+	checkIssues(t, prism, []wantIssue{
+		{
+			name:       "Git Configuration - Detect",
+			rating:     PrismDataStructs.RiskMedium,
+			hosts:      []string{"shop.example.com 10.0.0.10:443/tcp", "10.0.0.11:8080/tcp"},
+			references: []string{"https://github.com/git/git/blob/master/Documentation/config.txt", "https://templates.nuclei.sh/public/git-config"},
+		},
+		{
+			// nuclei's unknown severity is rated Info
+			name:   "Wappalyzer Technology Detection",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{"shop.example.com 10.0.0.10:443/tcp"},
+		},
+	})
+
+	checkDetails(t, "nuclei.jsonl", prism.Issues[0], "Host: shop.example.com", "Host: 10.0.0.11:8080", "/.git/config")
+	checkDetails(t, "nuclei.jsonl", prism.Issues[1], "Extracted Results:", "django")
+}
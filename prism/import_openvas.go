@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// openvasResult is one NVT result on one host and port from a Greenbone or
+// OpenVAS XML report
+type openvasResult struct {
+	Host struct {
+		IP       string `xml:",chardata"`
+		Hostname string `xml:"hostname"`
+	} `xml:"host"`
+	Port string `xml:"port"`
+	Nvt  struct {
+		OID      string `xml:"oid,attr"`
+		Name     string `xml:"name"`
+		Tags     string `xml:"tags"`
+		Solution string `xml:"solution"`
+
+		// GVM 9 and later list every reference here
+		Refs []struct {
+			Type string `xml:"type,attr"`
+			ID   string `xml:"id,attr"`
+		} `xml:"refs>ref"`
+
+		// Older reports list CVEs and URLs comma separated instead
+		Cve  string `xml:"cve"`
+		Xref string `xml:"xref"`
+
+		Severities []struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:"value"`
+		} `xml:"severities>severity"`
+	} `xml:"nvt"`
+	Severity         string `xml:"severity"`
+	Description      string `xml:"description"`
+	CreationTime     string `xml:"creation_time"`
+	ModificationTime string `xml:"modification_time"`
+}
+
+// openvasHost is a host summary at the end of a report, holding the
+// operating system the scan detected
+type openvasHost struct {
+	IP      string `xml:"ip"`
+	Details []struct {
+		Name  string `xml:"name"`
+		Value string `xml:"value"`
+	} `xml:"detail"`
+}
+
+// Ports such as 443/tcp, and the www (80/tcp) form older reports use.
+// Findings about the host as a whole are on general/tcp.
+var openvasPortRegex = regexp.MustCompile(`(\d+)/([a-z]+)`)
+
+// importOpenvas converts a Greenbone or OpenVAS XML report to one issue per
+// NVT, streaming it a result at a time
+func importOpenvas(r io.Reader) (*PrismDataStructs.Prism, error) {
+	issues := newIssueBuilder()
+	var summaries []openvasHost
+
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "host" {
+			var summary openvasHost
+			if err = dec.DecodeElement(&summary, &start); err != nil {
+				return nil, err
+			}
+			summaries = append(summaries, summary)
+			continue
+		}
+		if start.Name.Local != "result" {
+			continue
+		}
+
+		var result openvasResult
+		if err = dec.DecodeElement(&result, &start); err != nil {
+			return nil, err
+		}
+
+		// Negative severities mark false positives and scan errors
+		severity, err := strconv.ParseFloat(strings.TrimSpace(result.Severity), 64)
+		if err != nil || severity < 0 || result.Nvt.OID == "" {
+			continue
+		}
+
+		affectedHost := result.affectedHost()
+		if issue := issues.issue(result.Nvt.OID); issue != nil {
+			issues.addHost(result.Nvt.OID, affectedHost)
+			issues.addDetails(result.Nvt.OID, openvasTechnicalDetails(affectedHost, result))
+			addCves(issue, result.cves())
+			continue
+		}
+		issues.add(result.Nvt.OID, openvasIssue(result, severity, affectedHost))
+	}
+	prism := issues.prism()
+
+	// The host summaries come after the results, so the operating systems
+	// are filled in last. Addresses are compared by host key, so the
+	// summary's and the result's forms of an IPv6 address match.
+	for _, summary := range summaries {
+		ip := PrismDataStructs.NewHostKey(summary.IP, "", 0, "").IP
+		if ip == "" {
+			continue
+		}
+		operatingSystem, cpe := summary.detail("best_os_txt"), summary.detail("best_os_cpe")
+		for i := range prism.Issues {
+			for j := range prism.Issues[i].AffectedHosts {
+				affectedHost := &prism.Issues[i].AffectedHosts[j]
+				if affectedHost.Key().IP != ip {
+					continue
+				}
+				if operatingSystem != "" {
+					operatingSystem := operatingSystem
+					affectedHost.OperatingSystem = &operatingSystem
+				}
+				if cpe != "" {
+					affectedHost.Cpes = &[]string{cpe}
+				}
+			}
+		}
+	}
+	return prism, nil
+}
+
+// openvasIssue creates the issue for the first result of an NVT
+func openvasIssue(result openvasResult, severity float64, affectedHost PrismDataStructs.AffectedHost) PrismDataStructs.Issue {
+	tags := result.tags()
+
+	var issue PrismDataStructs.Issue
+	issue.Name = result.Nvt.Name
+	issue.OriginalRiskRating = PrismDataStructs.RiskRatingFromCVSS(severity)
+	issue.Status = "open"
+	issue.ConfirmedAt = result.confirmedAt()
+
+	if tags["summary"] != "" {
+		summary := htmlParagraphs(tags["summary"])
+		issue.Summary = &summary
+	}
+
+	// The insight explains the vulnerability, with its impact and what it
+	// affects following
+	var finding []string
+	for _, tag := range []string{"insight", "impact", "affected"} {
+		if tags[tag] != "" {
+			finding = append(finding, tags[tag])
+		}
+	}
+	if finding == nil {
+		finding = append(finding, tags["summary"])
+	}
+	issue.Finding = htmlParagraphs(strings.Join(finding, "\n\n"))
+
+	solution := result.Nvt.Solution
+	if solution == "" {
+		solution = tags["solution"]
+	}
+	if solution != "" {
+		recommendation := htmlParagraphs(solution)
+		issue.Recommendation = &recommendation
+	}
+
+	if vector := result.cvssVector(tags); vector != "" {
+		issue.CvssVector = &vector
+	}
+
+	for _, ref := range result.Nvt.Refs {
+		if ref.Type == "url" {
+			issue.References = append(issue.References, ref.ID)
+		}
+	}
+	for _, xref := range strings.Split(result.Nvt.Xref, ",") {
+		if reference := strings.TrimSpace(xref); strings.HasPrefix(reference, "URL:") {
+			issue.References = append(issue.References, strings.TrimPrefix(reference, "URL:"))
+		}
+	}
+
+	addCves(&issue, result.cves())
+	issue.AffectedHosts = []PrismDataStructs.AffectedHost{affectedHost}
+	issue.TechnicalDetails = "<p>NVT OID: " + result.Nvt.OID + "</p>" + openvasTechnicalDetails(affectedHost, result)
+	return issue
+}
+
+// openvasTechnicalDetails shows the result description for one host
+func openvasTechnicalDetails(host PrismDataStructs.AffectedHost, result openvasResult) string {
+	if strings.TrimSpace(result.Description) == "" {
+		return ""
+	}
+	return htmlSection("Host: "+host.Key().String(), htmlParagraphs(result.Description))
+}
+
+// tags splits the NVT's key=value|key=value tags
+func (r openvasResult) tags() map[string]string {
+	tags := make(map[string]string)
+	var last string
+	for _, tag := range strings.Split(r.Nvt.Tags, "|") {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || strings.ContainsAny(key, " \n") {
+			// A | inside the previous value
+			if last != "" {
+				tags[last] += "|" + tag
+			}
+			continue
+		}
+		tags[key] = value
+		last = key
+	}
+	return tags
+}
+
+// cvssVector prefers a CVSS v3 vector from the severities over the NVT's
+// CVSS v2 base vector
+func (r openvasResult) cvssVector(tags map[string]string) string {
+	for _, severity := range r.Nvt.Severities {
+		if strings.HasPrefix(severity.Value, "CVSS:") {
+			return severity.Value
+		}
+	}
+	if vector := tags["cvss_base_vector"]; vector != "" {
+		return vector
+	}
+	for _, severity := range r.Nvt.Severities {
+		if severity.Value != "" {
+			return severity.Value
+		}
+	}
+	return ""
+}
+
+// cves lists the NVT's CVEs from either reference format
+func (r openvasResult) cves() []string {
+	var cves []string
+	for _, ref := range r.Nvt.Refs {
+		if strings.EqualFold(ref.Type, "cve") {
+			cves = append(cves, ref.ID)
+		}
+	}
+	for _, cve := range strings.Split(r.Nvt.Cve, ",") {
+		if cve = strings.TrimSpace(cve); strings.HasPrefix(strings.ToUpper(cve), "CVE-") {
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
+
+// affectedHost builds the host and port the result was found on
+func (r openvasResult) affectedHost() PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+	affectedHost.Ip = strings.TrimSpace(r.Host.IP)
+	affectedHost.Hostname = strings.TrimSpace(r.Host.Hostname)
+	if affectedHost.Ip == "" {
+		affectedHost.Ip = affectedHost.Hostname
+	}
+
+	if m := openvasPortRegex.FindStringSubmatch(r.Port); m != nil {
+		port, _ := strconv.Atoi(m[1])
+		protocol := m[2]
+		affectedHost.Port = PrismDataStructs.NewPort(port)
+		affectedHost.Protocol = &protocol
+
+		// Older reports name the service before the port
+		if service, _, ok := strings.Cut(r.Port, " ("); ok && service != "general" {
+			affectedHost.Service = &service
+		}
+	}
+	return affectedHost
+}
+
+// confirmedAt is the date of the result, or today
+func (r openvasResult) confirmedAt() string {
+	for _, value := range []string{r.ModificationTime, r.CreationTime} {
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return time.Now().Format("2006-01-02")
+}
+
+func (h openvasHost) detail(name string) string {
+	for _, detail := range h.Details {
+		if detail.Name == name {
+			return strings.TrimSpace(detail.Value)
+		}
+	}
+	return ""
+}
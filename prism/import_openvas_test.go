@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportOpenvas(t *testing.T) {
+	prism := importFixture(t, "openvas", "openvas.xml")
+	checkIssues(t, prism, []wantIssue{
+		{
+			name:       "OpenSSL Denial of Service Vulnerability (20220315)",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"web01.example.com 10.0.0.1:443/tcp", "[2001:db8::10]:8443/tcp"},
+			cves:       []string{"CVE-2022-0778"},
+			cvss:       "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+			references: []string{"https://www.openssl.org/news/secadv/20220315.txt"},
+		},
+		{
+			name:       "TCP Timestamps Information Disclosure",
+			rating:     PrismDataStructs.RiskLow,
+			hosts:      []string{"2001:db8::10"},
+			cvss:       "AV:N/AC:H/Au:N/C:P/I:N/A:N",
+			references: []string{"https://datatracker.ietf.org/doc/html/rfc1323"},
+		},
+	})
+
+	// The report writes the second host's address in full, with spaces
+	for _, issue := range prism.Issues {
+		for _, host := range issue.AffectedHosts {
+			want := "Debian GNU/Linux 11"
+			if host.Ip == "10.0.0.1" {
+				want = "Ubuntu 20.04"
+			}
+			if host.OperatingSystem == nil || *host.OperatingSystem != want {
+				t.Errorf("%s %s: operating system = %v, want %q", issue.Name, host.Key(), host.OperatingSystem, want)
+			}
+			if host.Cpes == nil || len(*host.Cpes) != 1 {
+				t.Errorf("%s %s: cpes = %v, want the summary's best_os_cpe", issue.Name, host.Key(), host.Cpes)
+			}
+		}
+	}
+}
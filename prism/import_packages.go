@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// packageVuln is one vulnerability a container scanner found in a package
+type packageVuln struct {
+	id          string
+	title       string
+	description string
+	rating      PrismDataStructs.RiskRating
+	installed   string
+	url         string
+	exploited   bool
+
+	// fixed is the version that fixes the vulnerability, or a comma
+	// separated list of the fix on each release branch
+	fixed string
+
+	// aliases are other IDs of the vulnerability, such as the CVE of a
+	// GitHub advisory
+	aliases []string
+
+	// vector is the CVSS vector with the highest score, v3 when there is one
+	vector string
+	score  float64
+}
+
+// packageIssue collects the vulnerabilities of one package across every
+// scanned image
+type packageIssue struct {
+	name   string
+	hosts  *PrismDataStructs.HostSet
+	vulns  map[PrismDataStructs.HostKey][]packageVuln
+	worst  packageVuln
+	vector packageVuln
+}
+
+// packageFindings groups scanner results into one issue per package, so a
+// base image with hundreds of CVEs becomes a handful of upgrades
+type packageFindings struct {
+	issues map[string]*packageIssue
+	order  []string
+}
+
+func newPackageFindings() *packageFindings {
+	return &packageFindings{issues: make(map[string]*packageIssue)}
+}
+
+// add records a vulnerability of a package in the image host
+func (f *packageFindings) add(host PrismDataStructs.AffectedHost, pkg string, vuln packageVuln) {
+	issue, ok := f.issues[pkg]
+	if !ok {
+		issue = &packageIssue{
+			name:  pkg,
+			hosts: PrismDataStructs.NewHostSet(),
+			vulns: make(map[PrismDataStructs.HostKey][]packageVuln),
+			worst: vuln,
+		}
+		f.issues[pkg] = issue
+		f.order = append(f.order, pkg)
+	}
+
+	issue.hosts.Add(host)
+	key := host.Key()
+	issue.vulns[key] = append(issue.vulns[key], vuln)
+
+	if vuln.rating.Compare(issue.worst.rating) > 0 {
+		issue.worst = vuln
+	}
+	if vuln.vector != "" && vuln.betterVector(issue.vector) {
+		issue.vector = vuln
+	}
+}
+
+// betterVector reports whether the vulnerability's CVSS vector should be the
+// issue's rather than other's, preferring version 3 then the higher score
+func (v packageVuln) betterVector(other packageVuln) bool {
+	v3, otherV3 := strings.HasPrefix(v.vector, "CVSS:3"), strings.HasPrefix(other.vector, "CVSS:3")
+	if other.vector == "" || v3 != otherV3 {
+		return other.vector == "" || v3
+	}
+	return v.score > other.score
+}
+
+// prism builds the issues in the order their packages were first seen
+func (f *packageFindings) prism() *PrismDataStructs.Prism {
+	prism := &PrismDataStructs.Prism{Version: PrismDataStructs.CurrentVersion}
+	today := time.Now().Format("2006-01-02")
+
+	for _, pkg := range f.order {
+		p := f.issues[pkg]
+
+		issue := PrismDataStructs.Issue{
+			Name:               "Vulnerable Package: " + p.name,
+			OriginalRiskRating: p.worst.rating,
+			Status:             "open",
+			ConfirmedAt:        today,
+			AffectedHosts:      p.hosts.Hosts(),
+		}
+
+		var ids, cves, fixed []string
+		for _, key := range p.hosts.Keys() {
+			vulns := p.vulns[key]
+			sort.SliceStable(vulns, func(i, j int) bool {
+				if c := vulns[i].rating.Compare(vulns[j].rating); c != 0 {
+					return c > 0
+				}
+				return vulns[i].id < vulns[j].id
+			})
+
+			rows := [][]string{{"Vulnerability", "Severity", "Installed Version", "Fixed Version"}}
+			for _, vuln := range vulns {
+				rows = append(rows, []string{vuln.id, string(vuln.rating), vuln.installed, vuln.fixed})
+
+				if !containsString(ids, vuln.id) {
+					ids = append(ids, vuln.id)
+				}
+				for _, id := range append([]string{vuln.id}, vuln.aliases...) {
+					if strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+						cves = append(cves, id)
+					}
+				}
+				// Scanners list the fix of each release branch, such as
+				// "1.2.3, 1.3.0"
+				for _, version := range strings.Split(vuln.fixed, ",") {
+					if version = strings.TrimSpace(version); version != "" && !containsString(fixed, version) {
+						fixed = append(fixed, version)
+					}
+				}
+				if vuln.exploited {
+					exploitAvailable := true
+					issue.ExploitAvailable = &exploitAvailable
+				}
+			}
+			issue.TechnicalDetails += htmlSection("Image: "+key.Address(), htmlTable(rows...))
+		}
+		addCves(&issue, cves)
+		leadCves(&issue, append([]string{p.worst.id}, p.worst.aliases...))
+
+		// A package can have hundreds of vulnerabilities, which the CVEs and
+		// the table already list, so only the most severe is referenced
+		if p.worst.url != "" {
+			issue.References = []string{p.worst.url}
+		}
+
+		if p.vector.vector != "" {
+			vector := p.vector.vector
+			issue.CvssVector = &vector
+		}
+
+		worst := p.worst.id
+		if p.worst.title != "" {
+			worst += " (" + p.worst.title + ")"
+		}
+		finding := fmt.Sprintf("The installed version of the %s package was affected by the known vulnerability %s.", p.name, worst)
+		if len(ids) > 1 {
+			finding = fmt.Sprintf("The installed version of the %s package was affected by %d known vulnerabilities, the most severe of which was %s.", p.name, len(ids), worst)
+		}
+		issue.Finding = htmlParagraphs(finding) + htmlParagraphs(p.worst.description)
+
+		var recommendation string
+		switch len(fixed) {
+		case 0:
+			recommendation = fmt.Sprintf("It is recommended that the %s package is updated as soon as a fixed version is released, or removed from the image if it is not required.", p.name)
+		case 1:
+			recommendation = fmt.Sprintf("It is recommended that the %s package is updated to version %s or later.", p.name, fixed[0])
+		default:
+			recommendation = fmt.Sprintf("It is recommended that the %s package is updated to the latest version, which fixes each of the vulnerabilities listed in the technical details.", p.name)
+		}
+		recommendation = htmlParagraphs(recommendation)
+		issue.Recommendation = &recommendation
+
+		prism.Issues = append(prism.Issues, issue)
+	}
+	return prism
+}
+
+// imageAffectedHost names the host after the scanned image, or the path of
+// a filesystem or repository scan
+func imageAffectedHost(image, operatingSystem string) PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+	affectedHost.Name = &image
+	if operatingSystem = strings.TrimSpace(operatingSystem); operatingSystem != "" {
+		affectedHost.OperatingSystem = &operatingSystem
+	}
+	return affectedHost
+}
+
+// leadCves moves the given CVEs of an issue to the front of its list, so the
+// CVEs of the most severe vulnerability survive the list being truncated
+func leadCves(issue *PrismDataStructs.Issue, ids []string) {
+	if issue.Cves == nil {
+		return
+	}
+
+	// addCves stores CVEs in upper case
+	leading := make(map[string]bool)
+	for _, id := range ids {
+		leading[strings.ToUpper(strings.TrimSpace(id))] = true
+	}
+
+	var lead, rest []string
+	for _, cve := range *issue.Cves {
+		if leading[cve] {
+			lead = append(lead, cve)
+		} else {
+			rest = append(rest, cve)
+		}
+	}
+	cves := append(lead, rest...)
+	issue.Cves = &cves
+}
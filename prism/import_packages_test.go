@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+const packageImage = "registry.example.com/shop:1.4"
+
+func TestImportTrivy(t *testing.T) {
+	prism := importFixture(t, "trivy", "trivy.json")
+	checkIssues(t, prism, []wantIssue{
+		{
+			// The more severe vulnerability rates the issue, the v3 vector
+			// with the higher score is kept
+			name:       "Vulnerable Package: openssl",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{packageImage},
+			cves:       []string{"CVE-2023-0286", "CVE-2023-0464"},
+			cvss:       "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+			references: []string{"https://avd.aquasec.com/nvd/cve-2023-0286"},
+		},
+		{
+			name:   "Vulnerable Package: e2fsprogs",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{packageImage},
+			cves:   []string{"CVE-2022-1304"},
+			cvss:   "AV:L/AC:M/Au:N/C:P/I:P/A:P",
+		},
+		{
+			// The severity source's score is preferred to NVD's
+			name:       "Vulnerable Package: semver",
+			rating:     PrismDataStructs.RiskMedium,
+			hosts:      []string{packageImage},
+			cvss:       "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L",
+			references: []string{"https://github.com/advisories/GHSA-c2qf-rxjj-qqgw"},
+		},
+	})
+
+	checkPackageRecommendations(t, prism,
+		"<p>It is recommended that the openssl package is updated to the latest version, which fixes each of the vulnerabilities listed in the technical details.</p>",
+		"<p>It is recommended that the e2fsprogs package is updated as soon as a fixed version is released, or removed from the image if it is not required.</p>",
+		"<p>It is recommended that the semver package is updated to the latest version, which fixes each of the vulnerabilities listed in the technical details.</p>",
+	)
+	checkDetails(t, "trivy.json", prism.Issues[2], "Image: "+packageImage, "GHSA-c2qf-rxjj-qqgw", "7.5.2, 6.3.1, 5.7.2")
+	if host := prism.Issues[0].AffectedHosts[0]; host.OperatingSystem == nil || *host.OperatingSystem != "debian 11.6" {
+		t.Errorf("operating system = %v, want the image's", host.OperatingSystem)
+	}
+}
+
+func TestImportGrype(t *testing.T) {
+	prism := importFixture(t, "grype", "grype.json")
+	checkIssues(t, prism, []wantIssue{
+		{
+			// The related NVD record gives the CVE and the higher scored vector
+			name:       "Vulnerable Package: semver",
+			rating:     PrismDataStructs.RiskMedium,
+			hosts:      []string{packageImage},
+			cves:       []string{"CVE-2022-25883"},
+			cvss:       "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+			references: []string{"https://github.com/advisories/GHSA-c2qf-rxjj-qqgw"},
+		},
+		{
+			name:       "Vulnerable Package: openssl",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{packageImage},
+			cves:       []string{"CVE-2023-0286"},
+			cvss:       "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H",
+			references: []string{"https://security-tracker.debian.org/tracker/CVE-2023-0286"},
+		},
+		{
+			name:   "Vulnerable Package: e2fsprogs",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{packageImage},
+			cves:   []string{"CVE-2022-1304"},
+		},
+	})
+
+	checkPackageRecommendations(t, prism,
+		"<p>It is recommended that the semver package is updated to the latest version, which fixes each of the vulnerabilities listed in the technical details.</p>",
+		"<p>It is recommended that the openssl package is updated to version 1.1.1n-0+deb11u4 or later.</p>",
+		"<p>It is recommended that the e2fsprogs package is updated as soon as a fixed version is released, or removed from the image if it is not required.</p>",
+	)
+	if exploited := prism.Issues[1].ExploitAvailable; exploited == nil || !*exploited {
+		t.Errorf("exploit available = %v, want true for a known exploited vulnerability", exploited)
+	}
+	if exploited := prism.Issues[0].ExploitAvailable; exploited != nil {
+		t.Errorf("exploit available = %v, want nil", *exploited)
+	}
+}
+
+func TestPackageCvesKeepTheWorst(t *testing.T) {
+	// Enough low rated CVEs to be truncated, numbered so that sorting them
+	// as text would put CVE-2021-10000 before CVE-2021-9999
+	findings := newPackageFindings()
+	host := PrismDataStructs.AffectedHost{Hostname: packageImage}
+	for number := 9000; number < 11000; number++ {
+		id := fmt.Sprintf("CVE-2021-%d", number)
+		findings.add(host, "openssl", packageVuln{id: id, rating: PrismDataStructs.RiskLow})
+	}
+	findings.add(host, "openssl", packageVuln{id: "GHSA-9999-9999-9999", aliases: []string{"cve-2024-0727"}, rating: PrismDataStructs.RiskCritical})
+
+	issue := findings.prism().Issues[0]
+	cves := *issue.Cves
+	if cves[0] != "CVE-2024-0727" || cves[1] != "CVE-2021-9000" {
+		t.Fatalf("cves start %q, want the worst vulnerability's then the rest in order", cves[:2])
+	}
+	for i := 2; i < len(cves); i++ {
+		if !cveLess(cves[i-1], cves[i]) {
+			t.Fatalf("%s is sorted before %s", cves[i-1], cves[i])
+		}
+	}
+
+	if dropped := issue.TruncateCves(); dropped == 0 || (*issue.Cves)[0] != "CVE-2024-0727" {
+		t.Errorf("dropped %d CVEs, kept %q first, want the worst vulnerability's kept", dropped, (*issue.Cves)[0])
+	}
+}
+
+// checkPackageRecommendations compares each issue's recommendation, in order
+func checkPackageRecommendations(t *testing.T, prism *PrismDataStructs.Prism, want ...string) {
+	t.Helper()
+	for i, want := range want {
+		issue := prism.Issues[i]
+		if issue.Recommendation == nil || *issue.Recommendation != want {
+			t.Errorf("%s: recommendation = %v, want %q", issue.Name, issue.Recommendation, want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// qualysHost is an IP from a Qualys VM scan report (the SCAN XML format)
+type qualysHost struct {
+	IP   string `xml:"value,attr"`
+	Name string `xml:"name,attr"`
+	OS   string `xml:"OS"`
+
+	// Confirmed vulnerabilities, potential vulnerabilities and information
+	// gathered, each grouped by category
+	Vulns     []qualysCategory `xml:"VULNS>CAT"`
+	Practices []qualysCategory `xml:"PRACTICES>CAT"`
+	Infos     []qualysCategory `xml:"INFOS>CAT"`
+}
+
+// qualysCategory groups the findings on one port
+type qualysCategory struct {
+	Port     int          `xml:"port,attr"`
+	Protocol string       `xml:"protocol,attr"`
+	Vulns    []qualysVuln `xml:"VULN"`
+	Practice []qualysVuln `xml:"PRACTICE"`
+	Infos    []qualysVuln `xml:"INFO"`
+}
+
+// qualysVuln is one QID found on one host and port
+type qualysVuln struct {
+	QID         int64  `xml:"number,attr"`
+	Severity    int    `xml:"severity,attr"`
+	Title       string `xml:"TITLE"`
+	Diagnosis   string `xml:"DIAGNOSIS"`
+	Consequence string `xml:"CONSEQUENCE"`
+	Solution    string `xml:"SOLUTION"`
+	Result      string `xml:"RESULT"`
+
+	// Scores are empty when Qualys has none, and the vectors are only in
+	// reports that include them
+	CvssBase      string `xml:"CVSS_BASE"`
+	CvssTemporal  string `xml:"CVSS_TEMPORAL"`
+	CvssVector    string `xml:"CVSS_VECTOR"`
+	Cvss3Base     string `xml:"CVSS3_BASE"`
+	Cvss3Temporal string `xml:"CVSS3_TEMPORAL"`
+	Cvss3Vector   string `xml:"CVSS3_VECTOR"`
+	Cvss3Version  string `xml:"CVSS3_VERSION"`
+	Cves          []struct {
+		ID string `xml:"ID"`
+	} `xml:"CVE_ID_LIST>CVE_ID"`
+	VendorReferences []struct {
+		URL string `xml:"URL"`
+	} `xml:"VENDOR_REFERENCE_LIST>VENDOR_REFERENCE"`
+}
+
+// Qualys severities 1 to 5
+var qualysRatings = []PrismDataStructs.RiskRating{
+	PrismDataStructs.RiskInfo,
+	PrismDataStructs.RiskLow,
+	PrismDataStructs.RiskMedium,
+	PrismDataStructs.RiskHigh,
+	PrismDataStructs.RiskCritical,
+}
+
+// importQualys converts a Qualys VM scan report to one issue per QID,
+// streaming it a host at a time. Information gathered is rated Info
+// whatever its severity.
+func importQualys(r io.Reader) (*PrismDataStructs.Prism, error) {
+	issues := newIssueBuilder()
+	confirmedAt := time.Now().Format("2006-01-02")
+
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		// The header's DATE key is when the scan was launched
+		if start.Name.Local == "KEY" && qualysKey(start) == "DATE" {
+			var date string
+			if err = dec.DecodeElement(&date, &start); err != nil {
+				return nil, err
+			}
+			if t, err := time.Parse(time.RFC3339, strings.TrimSpace(date)); err == nil {
+				confirmedAt = t.Format("2006-01-02")
+			}
+			continue
+		}
+		if start.Name.Local != "IP" {
+			continue
+		}
+
+		var host qualysHost
+		if err = dec.DecodeElement(&host, &start); err != nil {
+			return nil, err
+		}
+
+		for _, finding := range host.findings() {
+			affectedHost := host.affectedHost(finding.category)
+
+			key := strconv.FormatInt(finding.vuln.QID, 10)
+			if issue := issues.issue(key); issue != nil {
+				issues.addHost(key, affectedHost)
+				issues.addDetails(key, qualysTechnicalDetails(affectedHost, finding.vuln))
+				addCves(issue, finding.vuln.cves())
+				continue
+			}
+
+			issue := qualysIssue(finding.vuln, finding.info, confirmedAt)
+			issue.AffectedHosts = []PrismDataStructs.AffectedHost{affectedHost}
+			issue.TechnicalDetails = "<p>QID: " + key + "</p>" + finding.vuln.scores() + qualysTechnicalDetails(affectedHost, finding.vuln)
+			issues.add(key, issue)
+		}
+	}
+	return issues.prism(), nil
+}
+
+// qualysFinding is a QID on a host with the category it was listed under
+type qualysFinding struct {
+	vuln     qualysVuln
+	category qualysCategory
+	info     bool
+}
+
+// findings lists every QID on the host, vulnerabilities first
+func (h qualysHost) findings() []qualysFinding {
+	var findings []qualysFinding
+	for _, categories := range [][]qualysCategory{h.Vulns, h.Practices, h.Infos} {
+		for _, category := range categories {
+			for _, vuln := range category.Vulns {
+				findings = append(findings, qualysFinding{vuln: vuln, category: category})
+			}
+			for _, vuln := range category.Practice {
+				findings = append(findings, qualysFinding{vuln: vuln, category: category})
+			}
+			for _, vuln := range category.Infos {
+				findings = append(findings, qualysFinding{vuln: vuln, category: category, info: true})
+			}
+		}
+	}
+	return findings
+}
+
+// qualysIssue creates the issue for the first result of a QID
+func qualysIssue(vuln qualysVuln, info bool, confirmedAt string) PrismDataStructs.Issue {
+	var issue PrismDataStructs.Issue
+	issue.Name = strings.TrimSpace(vuln.Title)
+	issue.Finding = htmlBlock(vuln.Diagnosis) + htmlBlock(vuln.Consequence)
+	issue.Status = "open"
+	issue.ConfirmedAt = confirmedAt
+
+	// Rate by the CVSS score, v3 when Qualys has one, falling back to the
+	// Qualys severity
+	issue.OriginalRiskRating = PrismDataStructs.RiskInfo
+	if !info {
+		if vuln.Severity >= 1 && vuln.Severity <= len(qualysRatings) {
+			issue.OriginalRiskRating = qualysRatings[vuln.Severity-1]
+		}
+		for _, score := range []string{vuln.Cvss3Base, vuln.CvssBase} {
+			if cvss, err := strconv.ParseFloat(strings.TrimSpace(score), 64); err == nil {
+				issue.OriginalRiskRating = PrismDataStructs.RiskRatingFromCVSS(cvss)
+				break
+			}
+		}
+	}
+
+	if vector := vuln.vector(); vector != "" {
+		issue.CvssVector = &vector
+	}
+
+	if solution := htmlBlock(vuln.Solution); solution != "" {
+		issue.Recommendation = &solution
+	}
+
+	for _, reference := range vuln.VendorReferences {
+		if url := strings.TrimSpace(reference.URL); url != "" {
+			issue.References = append(issue.References, url)
+		}
+	}
+
+	addCves(&issue, vuln.cves())
+	return issue
+}
+
+// qualysTechnicalDetails shows the scan result for one host. Qualys writes
+// tabular results tab separated, and those become a table.
+func qualysTechnicalDetails(host PrismDataStructs.AffectedHost, vuln qualysVuln) string {
+	result := strings.TrimSpace(strings.ReplaceAll(vuln.Result, "\r\n", "\n"))
+	if result == "" {
+		return ""
+	}
+
+	lines := strings.Split(result, "\n")
+	if !strings.Contains(lines[0], "\t") {
+		return htmlSection("Host: "+host.Key().String(), htmlParagraphs(result))
+	}
+	rows := make([][]string, len(lines))
+	for i, line := range lines {
+		rows[i] = strings.Split(line, "\t")
+	}
+	return htmlSection("Host: "+host.Key().String(), htmlTable(rows...))
+}
+
+// vector is the QID's CVSS vector, v3 when Qualys has one. Qualys writes v3
+// vectors without the CVSS:3.x prefix, which is added from CVSS3_VERSION.
+func (v qualysVuln) vector() string {
+	if vector := strings.TrimSpace(v.Cvss3Vector); vector != "" {
+		if !strings.HasPrefix(strings.ToUpper(vector), "CVSS:") {
+			version := strings.TrimSpace(v.Cvss3Version)
+			if version == "" {
+				version = "3.1"
+			}
+			vector = "CVSS:" + version + "/" + vector
+		}
+		return vector
+	}
+	return strings.TrimSpace(v.CvssVector)
+}
+
+// scores lists the QID's CVSS base and temporal scores for the technical
+// details
+func (v qualysVuln) scores() string {
+	var rows [][]string
+	for _, score := range []struct{ version, base, temporal string }{
+		{"CVSS v3", v.Cvss3Base, v.Cvss3Temporal},
+		{"CVSS v2", v.CvssBase, v.CvssTemporal},
+	} {
+		base, temporal := strings.TrimSpace(score.base), strings.TrimSpace(score.temporal)
+		if base != "" || temporal != "" {
+			rows = append(rows, []string{score.version, base, temporal})
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return htmlTable(append([][]string{{"Version", "Base Score", "Temporal Score"}}, rows...)...)
+}
+
+func (v qualysVuln) cves() []string {
+	var cves []string
+	for _, cve := range v.Cves {
+		cves = append(cves, cve.ID)
+	}
+	return cves
+}
+
+// affectedHost builds the host and, for port categories, the port a QID was
+// found on
+func (h qualysHost) affectedHost(category qualysCategory) PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+	affectedHost.Ip = h.IP
+	if h.Name != "" && h.Name != "No registered hostname" {
+		affectedHost.Hostname = h.Name
+	}
+	if os := strings.TrimSpace(h.OS); os != "" {
+		affectedHost.OperatingSystem = &os
+	}
+
+	if category.Port != 0 {
+		protocol := category.Protocol
+		affectedHost.Port = PrismDataStructs.NewPort(category.Port)
+		affectedHost.Protocol = &protocol
+	}
+	return affectedHost
+}
+
+func qualysKey(start xml.StartElement) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "value" {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportQualys(t *testing.T) {
+	prism := importFixture(t, "qualys", "qualys.xml")
+	checkIssues(t, prism, []wantIssue{
+		{
+			// Rated by the v3 score rather than the severity of 3
+			name:       "Birthday attacks against TLS ciphers with 64bit block size vulnerability (Sweet32)",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"web01.example.com 10.0.0.1:443/tcp", "10.0.0.2:8443/tcp"},
+			cves:       []string{"CVE-2016-2183"},
+			cvss:       "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+			references: []string{"https://sweet32.info/"},
+		},
+		{
+			name:   "Open TCP Services List",
+			rating: PrismDataStructs.RiskInfo,
+			hosts:  []string{"web01.example.com 10.0.0.1"},
+		},
+		{
+			name:   "EOL/Obsolete Software: OpenSSH Detected",
+			rating: PrismDataStructs.RiskMedium,
+			hosts:  []string{"10.0.0.2"},
+			cvss:   "AV:N/AC:M/Au:N/C:P/I:N/A:N",
+		},
+		{
+			// Without a score the Qualys severity of 2 rates it
+			name:   "Web Server Stopped Responding",
+			rating: PrismDataStructs.RiskLow,
+			hosts:  []string{"10.0.0.2:80/tcp"},
+		},
+	})
+
+	sweet32 := prism.Issues[0]
+	if sweet32.ConfirmedAt != "2024-03-12" {
+		t.Errorf("confirmed at = %q, want the header's date", sweet32.ConfirmedAt)
+	}
+	for _, want := range []string{
+		"<p>QID: 38657</p>",
+		">CVSS v3</td>",
+		">6.6</td>",
+		"DES-CBC3-SHA</td>",
+		"DES-CBC3-SHA is supported.",
+	} {
+		if !strings.Contains(sweet32.TechnicalDetails, want) {
+			t.Errorf("technical details missing %q", want)
+		}
+	}
+	if !strings.Contains(prism.Issues[1].TechnicalDetails, ">Service Detected</td>") {
+		t.Errorf("technical details = %q, want the tab separated result as a table", prism.Issues[1].TechnicalDetails)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// sarifLog is the part of a SARIF 2.1.0 log the importer reads
+type sarifLog struct {
+	Runs []struct {
+		Tool struct {
+			Driver     sarifComponent   `json:"driver"`
+			Extensions []sarifComponent `json:"extensions"`
+		} `json:"tool"`
+		VersionControlProvenance []struct {
+			RepositoryURI string `json:"repositoryUri"`
+		} `json:"versionControlProvenance"`
+		Results []sarifResult `json:"results"`
+	} `json:"runs"`
+}
+
+// sarifComponent is the tool, or a plugin of it, with the rules it ran
+type sarifComponent struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	Help             sarifMessage `json:"help"`
+	HelpURI          string       `json:"helpUri"`
+	Default          struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+	Properties struct {
+		Tags []string `json:"tags"`
+
+		// The CVSS style score GitHub code scanning rates security rules by
+		SecuritySeverity string `json:"security-severity"`
+	} `json:"properties"`
+}
+
+type sarifResult struct {
+	RuleID string `json:"ruleId"`
+	Rule   struct {
+		ID string `json:"id"`
+	} `json:"rule"`
+	Level     string       `json:"level"`
+	Message   sarifMessage `json:"message"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region        sarifRegion `json:"region"`
+			ContextRegion sarifRegion `json:"contextRegion"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+	Snippet   struct {
+		Text string `json:"text"`
+	} `json:"snippet"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIF result levels
+var sarifRatings = map[string]PrismDataStructs.RiskRating{
+	"error":   PrismDataStructs.RiskHigh,
+	"warning": PrismDataStructs.RiskMedium,
+	"note":    PrismDataStructs.RiskLow,
+	"none":    PrismDataStructs.RiskInfo,
+}
+
+var (
+	// CWE tags such as CWE-79, CWE-89: SQL Injection or CodeQL's external/cwe/cwe-079
+	cweTagRegex = regexp.MustCompile(`(?i)\bcwe[-/](\d+)\b`)
+
+	// OWASP Top 10 tags such as OWASP-A03:2021 - Injection
+	owaspCategoryRegex = regexp.MustCompile(`\b(A\d{2}):(\d{4})\b`)
+)
+
+// importSarif converts a SARIF log from a SAST or IaC scanner to one issue
+// per tool and rule. Each source file is an affected host, named by its path
+// and located in the scanned repository.
+func importSarif(r io.Reader) (*PrismDataStructs.Prism, error) {
+	var log sarifLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return nil, err
+	}
+
+	issues := newIssueBuilder()
+	files := make(map[string]map[PrismDataStructs.HostKey][][]string)
+	today := time.Now().Format("2006-01-02")
+
+	for _, run := range log.Runs {
+		rules := make(map[string]*sarifRule)
+		for _, component := range append([]sarifComponent{run.Tool.Driver}, run.Tool.Extensions...) {
+			for i := range component.Rules {
+				rules[component.Rules[i].ID] = &component.Rules[i]
+			}
+		}
+
+		var repository string
+		if len(run.VersionControlProvenance) > 0 {
+			repository = run.VersionControlProvenance[0].RepositoryURI
+		}
+
+		for _, result := range run.Results {
+			ruleID := result.RuleID
+			if ruleID == "" {
+				ruleID = result.Rule.ID
+			}
+			rule, ok := rules[ruleID]
+			if !ok {
+				rule = &sarifRule{ID: ruleID}
+			}
+			rating := rule.rating(result.Level)
+
+			key := run.Tool.Driver.Name + "\x00" + ruleID
+			issue := issues.issue(key)
+			if issue == nil {
+				issues.add(key, sarifIssue(run.Tool.Driver.Name, rule, result, today))
+				issue = issues.issue(key)
+				files[key] = make(map[PrismDataStructs.HostKey][][]string)
+			}
+			if rating.Compare(issue.OriginalRiskRating) > 0 {
+				issue.OriginalRiskRating = rating
+			}
+
+			for _, location := range result.Locations {
+				physical := location.PhysicalLocation
+				host := sarifAffectedHost(physical.ArtifactLocation.URI, repository)
+				issues.addHost(key, host)
+
+				region := physical.Region
+				snippet := region.Snippet.Text
+				if snippet == "" {
+					snippet = physical.ContextRegion.Snippet.Text
+				}
+				files[key][host.Key()] = append(files[key][host.Key()], []string{region.lines(), strings.TrimRight(snippet, "\r\n"), result.Message.Text})
+			}
+		}
+	}
+
+	// Each file's locations are tabled together, once every run is read
+	for key := range files {
+		for _, host := range issues.hostKeys(key) {
+			rows := append([][]string{{"Line", "Code", "Message"}}, files[key][host]...)
+			issues.addDetails(key, htmlSection("File: "+host.Address(), htmlTable(rows...)))
+		}
+	}
+	return issues.prism(), nil
+}
+
+// sarifIssue creates the issue for the first result of a rule
+func sarifIssue(tool string, rule *sarifRule, result sarifResult, today string) PrismDataStructs.Issue {
+	var issue PrismDataStructs.Issue
+	issue.Name = firstNonEmpty(rule.ShortDescription.Text, rule.Name, rule.ID)
+	issue.Finding = htmlParagraphs(firstNonEmpty(rule.FullDescription.Text, rule.ShortDescription.Text, result.Message.Text))
+	issue.OriginalRiskRating = rule.rating(result.Level)
+	issue.Status = "open"
+	issue.ConfirmedAt = today
+
+	// Rules without help of their own point to the tool's documentation
+	var recommendation string
+	switch {
+	case strings.TrimSpace(rule.Help.Text) != "" && rule.Help.Text != rule.FullDescription.Text:
+		recommendation = htmlParagraphs(rule.Help.Text)
+	case rule.HelpURI != "":
+		recommendation = htmlParagraphs("It is recommended that the flagged code is reviewed and remediated following the guidance at " + rule.HelpURI + ".")
+	default:
+		recommendation = htmlParagraphs("It is recommended that the flagged code is reviewed and remediated.")
+	}
+	issue.Recommendation = &recommendation
+
+	if rule.HelpURI != "" {
+		issue.References = append(issue.References, rule.HelpURI)
+	}
+	for _, tag := range rule.Properties.Tags {
+		for _, match := range cweTagRegex.FindAllStringSubmatch(tag, -1) {
+			cwe, _ := strconv.Atoi(match[1])
+			reference := "https://cwe.mitre.org/data/definitions/" + strconv.Itoa(cwe) + ".html"
+			if !containsString(issue.References, reference) {
+				issue.References = append(issue.References, reference)
+			}
+		}
+		if match := owaspCategoryRegex.FindStringSubmatch(tag); match != nil && issue.OwaspId == nil {
+			owaspId := match[1] + ":" + match[2]
+			issue.OwaspId = &owaspId
+		}
+	}
+
+	issue.TechnicalDetails = "<p>Rule: " + htmlLines(strings.TrimSpace(tool+" "+rule.ID)) + "</p>"
+	return issue
+}
+
+// rating prefers the rule's security severity score, then the result's
+// level, then the rule's default level, which SARIF defaults to warning
+func (r *sarifRule) rating(level string) PrismDataStructs.RiskRating {
+	if score, err := strconv.ParseFloat(r.Properties.SecuritySeverity, 64); err == nil {
+		return PrismDataStructs.RiskRatingFromCVSS(score)
+	}
+	if rating, ok := sarifRatings[level]; ok {
+		return rating
+	}
+	if rating, ok := sarifRatings[r.Default.Level]; ok {
+		return rating
+	}
+	return PrismDataStructs.RiskMedium
+}
+
+// lines formats the region's line range
+func (r sarifRegion) lines() string {
+	switch {
+	case r.StartLine == 0:
+		return ""
+	case r.EndLine > r.StartLine:
+		return strconv.Itoa(r.StartLine) + "-" + strconv.Itoa(r.EndLine)
+	}
+	return strconv.Itoa(r.StartLine)
+}
+
+// sarifAffectedHost names the host after the file, with the repository as
+// its location
+func sarifAffectedHost(uri, repository string) PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+	name := strings.TrimPrefix(uri, "file://")
+	affectedHost.Name = &name
+	if repository != "" {
+		affectedHost.Location = &repository
+	}
+	return affectedHost
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
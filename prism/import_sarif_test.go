@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportSarif(t *testing.T) {
+	prism := importFixture(t, "sarif", "results.sarif")
+	checkIssues(t, prism, []wantIssue{
+		{
+			// Rated by the security severity score rather than the level
+			name:   "SQL injection through a raw Django query",
+			rating: PrismDataStructs.RiskCritical,
+			hosts:  []string{"https://github.com/example/shop/shop/views.py", "https://github.com/example/shop/shop/orders.py"},
+			references: []string{
+				"https://semgrep.dev/r/python.django.security.injection.sql.sql-injection-using-raw",
+				"https://cwe.mitre.org/data/definitions/89.html",
+			},
+		},
+		{
+			name:   "Use of the MD5 hash",
+			rating: PrismDataStructs.RiskLow,
+			hosts:  []string{"https://github.com/example/shop/shop/views.py"},
+			references: []string{
+				"https://semgrep.dev/r/python.lang.security.audit.md5-used",
+				"https://cwe.mitre.org/data/definitions/327.html",
+			},
+		},
+		{
+			name:   "Ensure that a user for the container has been created",
+			rating: PrismDataStructs.RiskHigh,
+			hosts:  []string{"Dockerfile"},
+		},
+		{
+			// A result of a rule the log does not describe
+			name:   "CKV_K8S_20",
+			rating: PrismDataStructs.RiskMedium,
+			hosts:  []string{"deploy/app.yaml"},
+		},
+	})
+
+	injection := prism.Issues[0]
+	if injection.OwaspId == nil || *injection.OwaspId != "A03:2021" {
+		t.Errorf("owasp id = %v, want the OWASP tag's category", injection.OwaspId)
+	}
+	checkDetails(t, "results.sarif", injection, "Rule: Semgrep OSS python.django", "File: https://github.com/example/shop/shop/orders.py", ">42-43<", "User input reaches Order.objects.raw()")
+	checkDetails(t, "results.sarif", prism.Issues[1], "digest = hashlib.md5(data)")
+
+	// Rules fall back from their help to their help URI to the generic
+	// recommendation
+	for i, want := range []string{
+		"<p>Use the Django ORM or pass the values as query parameters.</p>",
+		"<p>It is recommended that the flagged code is reviewed and remediated following the guidance at https://semgrep.dev/r/python.lang.security.audit.md5-used.</p>",
+		"<p>It is recommended that the flagged code is reviewed and remediated.</p>",
+		"<p>It is recommended that the flagged code is reviewed and remediated.</p>",
+	} {
+		issue := prism.Issues[i]
+		if issue.Recommendation == nil || *issue.Recommendation != want {
+			t.Errorf("%s: recommendation = %v, want %q", issue.Name, issue.Recommendation, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// sslscanTest is one scanned host from sslscan's XML output (--xml)
+type sslscanTest struct {
+	Host      string `xml:"host,attr"`
+	Port      int    `xml:"port,attr"`
+	Protocols []struct {
+		Type    string `xml:"type,attr"`
+		Version string `xml:"version,attr"`
+		Enabled string `xml:"enabled,attr"`
+	} `xml:"protocol"`
+	Ciphers []struct {
+		SSLVersion string `xml:"sslversion,attr"`
+		Bits       string `xml:"bits,attr"`
+		Cipher     string `xml:"cipher,attr"`
+		Strength   string `xml:"strength,attr"`
+	} `xml:"cipher"`
+	Certificates []struct {
+		SignatureAlgorithm string `xml:"signature-algorithm"`
+		PK                 struct {
+			Type string `xml:"type,attr"`
+			Bits int    `xml:"bits,attr"`
+		} `xml:"pk"`
+		Subject    string `xml:"subject"`
+		SelfSigned string `xml:"self-signed"`
+		NotAfter   string `xml:"not-valid-after"`
+		Expired    string `xml:"expired"`
+	} `xml:"certificates>certificate"`
+}
+
+// Ratings of the deprecated protocols, as testssl.sh rates them
+var sslscanProtocols = map[string]PrismDataStructs.RiskRating{
+	"ssl 2":   PrismDataStructs.RiskCritical,
+	"ssl 3":   PrismDataStructs.RiskHigh,
+	"tls 1.0": PrismDataStructs.RiskLow,
+	"tls 1.1": PrismDataStructs.RiskLow,
+}
+
+// Ratings of sslscan's cipher strengths below strong
+var sslscanCipherStrengths = map[string]PrismDataStructs.RiskRating{
+	"null":      PrismDataStructs.RiskHigh,
+	"anonymous": PrismDataStructs.RiskHigh,
+	"weak":      PrismDataStructs.RiskMedium,
+	"medium":    PrismDataStructs.RiskLow,
+}
+
+// importSslscan converts sslscan XML output to the consolidated TLS issues.
+// sslscan does not check HTTP headers, so it never reports HSTS.
+func importSslscan(r io.Reader) (*PrismDataStructs.Prism, error) {
+	findings := newTLSFindings()
+
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "ssltest" {
+			continue
+		}
+
+		var test sslscanTest
+		if err = dec.DecodeElement(&test, &start); err != nil {
+			return nil, err
+		}
+		host := urlAffectedHost(test.Host+":"+strconv.Itoa(test.Port), "")
+
+		for _, protocol := range test.Protocols {
+			name := strings.ToLower(protocol.Type + " " + protocol.Version)
+			if rating, ok := sslscanProtocols[name]; ok && protocol.Enabled == "1" {
+				findings.add(tlsProtocols, host, rating, strings.ToUpper(protocol.Type)+"v"+protocol.Version, "offered")
+			}
+		}
+
+		for _, cipher := range test.Ciphers {
+			if rating, ok := sslscanCipherStrengths[cipher.Strength]; ok {
+				findings.add(tlsCiphers, host, rating, cipher.SSLVersion, cipher.Cipher, cipher.Bits, cipher.Strength)
+			}
+		}
+
+		for _, certificate := range test.Certificates {
+			if certificate.Expired == "true" {
+				findings.add(tlsCertificate, host, PrismDataStructs.RiskHigh, "Expiry", "expired "+certificate.NotAfter)
+			}
+			if certificate.SelfSigned == "true" {
+				findings.add(tlsCertificate, host, PrismDataStructs.RiskMedium, "Chain of trust", "self-signed certificate for "+certificate.Subject)
+			}
+			algorithm := strings.ToLower(certificate.SignatureAlgorithm)
+			if strings.Contains(algorithm, "md5") || strings.Contains(algorithm, "sha1") {
+				findings.add(tlsCertificate, host, PrismDataStructs.RiskMedium, "Signature algorithm", certificate.SignatureAlgorithm)
+			}
+			if certificate.PK.Type == "RSA" && certificate.PK.Bits > 0 && certificate.PK.Bits < 2048 {
+				findings.add(tlsCertificate, host, PrismDataStructs.RiskMedium, "Key size", "RSA "+strconv.Itoa(certificate.PK.Bits)+" bits")
+			}
+		}
+	}
+	return findings.prism(), nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// wantIssue is what an importer test expects of one issue
+type wantIssue struct {
+	name       string
+	rating     PrismDataStructs.RiskRating
+	hosts      []string // HostKey.String() of each affected host, in order
+	cves       []string
+	cvss       string
+	references []string
+}
+
+// importFixture runs the named importer over a file in testdata
+func importFixture(t *testing.T, name, fixture string) *PrismDataStructs.Prism {
+	t.Helper()
+	imp, err := findImporter(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	prism, err := imp.importFrom(newLogger("test"), f)
+	if err != nil {
+		t.Fatalf("%s: %v", fixture, err)
+	}
+	return prism
+}
+
+// checkIssues compares the issues, in order, with what the test expects
+func checkIssues(t *testing.T, prism *PrismDataStructs.Prism, want []wantIssue) {
+	t.Helper()
+	if len(prism.Issues) != len(want) {
+		var names []string
+		for _, issue := range prism.Issues {
+			names = append(names, issue.Name)
+		}
+		t.Fatalf("%d issues %q, want %d", len(prism.Issues), names, len(want))
+	}
+
+	for i, want := range want {
+		issue := &prism.Issues[i]
+		if issue.Name != want.name {
+			t.Errorf("issues[%d]: name = %q, want %q", i, issue.Name, want.name)
+		}
+		if issue.OriginalRiskRating != want.rating {
+			t.Errorf("%s: rating = %q, want %q", want.name, issue.OriginalRiskRating, want.rating)
+		}
+
+		var hosts []string
+		for _, host := range issue.AffectedHosts {
+			hosts = append(hosts, host.Key().String())
+		}
+		if !reflect.DeepEqual(hosts, want.hosts) {
+			t.Errorf("%s: hosts = %q, want %q", want.name, hosts, want.hosts)
+		}
+
+		var cves []string
+		if issue.Cves != nil {
+			cves = *issue.Cves
+		}
+		if !reflect.DeepEqual(cves, want.cves) {
+			t.Errorf("%s: cves = %q, want %q", want.name, cves, want.cves)
+		}
+
+		var cvss string
+		if issue.CvssVector != nil {
+			cvss = *issue.CvssVector
+		}
+		if cvss != want.cvss {
+			t.Errorf("%s: cvss vector = %q, want %q", want.name, cvss, want.cvss)
+		}
+
+		if !reflect.DeepEqual(issue.References, want.references) {
+			t.Errorf("%s: references = %q, want %q", want.name, issue.References, want.references)
+		}
+	}
+}
+
+func TestURLAffectedHost(t *testing.T) {
+	// A host name is never put in the ip field
+	for _, test := range []struct {
+		url, ip, want, wantIp string
+	}{
+		{"https://shop.example.com/search?q=1", "", "shop.example.com:443/tcp", ""},
+		{"http://shop.example.com:8080", "10.0.0.10", "shop.example.com 10.0.0.10:8080/tcp", "10.0.0.10"},
+		{"10.0.0.11:8443", "", "10.0.0.11:8443/tcp", "10.0.0.11"},
+		{"https://[2001:db8::10]/", "", "[2001:db8::10]:443/tcp", "2001:db8::10"},
+		{"shop.example.com", "", "shop.example.com", ""},
+	} {
+		host := urlAffectedHost(test.url, test.ip)
+		if got := host.Key().String(); got != test.want || host.Ip != test.wantIp {
+			t.Errorf("urlAffectedHost(%q, %q) = %q with ip %q, want %q with ip %q", test.url, test.ip, got, host.Ip, test.want, test.wantIp)
+		}
+	}
+}
+
+func TestIssueBuilder(t *testing.T) {
+	host := func(ip string) PrismDataStructs.AffectedHost {
+		return PrismDataStructs.AffectedHost{Ip: ip, Port: PrismDataStructs.NewPort(443)}
+	}
+
+	issues := newIssueBuilder()
+	issues.add("a", PrismDataStructs.Issue{Name: "A", AffectedHosts: []PrismDataStructs.AffectedHost{host("10.0.0.1")}, TechnicalDetails: "<p>1</p>"})
+	for i := 0; i < 1000; i++ {
+		issues.addHost("a", host("10.0.0.2"))
+		issues.addDetails("a", "<p>2</p>")
+	}
+	issues.add("b", PrismDataStructs.Issue{Name: "B"})
+	issues.addHost("b", host("10.0.0.1"))
+
+	prism := issues.prism()
+	if len(prism.Issues) != 2 || prism.Issues[0].Name != "A" || prism.Issues[1].Name != "B" {
+		t.Fatalf("issues = %v, want A then B", prism.Issues)
+	}
+	a := prism.Issues[0]
+	if len(a.AffectedHosts) != 2 || a.AffectedHosts[0].Ip != "10.0.0.1" || a.AffectedHosts[1].Ip != "10.0.0.2" {
+		t.Errorf("hosts = %v, want 10.0.0.1 then 10.0.0.2 once", a.AffectedHosts)
+	}
+	if len(a.TechnicalDetails) != len("<p>1</p>")+1000*len("<p>2</p>") {
+		t.Errorf("technical details are %d bytes, want every result's", len(a.TechnicalDetails))
+	}
+}
+
+// importerFixtures are the testdata files of each importer
+var importerFixtures = map[string][]string{
+	"burp":    {"burp.xml"},
+	"grype":   {"grype.json"},
+	"nessus":  {"scan.nessus"},
+	"nexpose": {"nexpose.xml"},
+	"nuclei":  {"nuclei.jsonl"},
+	"openvas": {"openvas.xml"},
+	"qualys":  {"qualys.xml"},
+	"sarif":   {"results.sarif"},
+	"sslscan": {"sslscan.xml"},
+	"testssl": {"testssl.json", "testssl-pretty.json"},
+	"trivy":   {"trivy.json"},
+	"zap":     {"zap.json", "zap.xml"},
+}
+
+func TestImportsAreValid(t *testing.T) {
+	for _, imp := range importers {
+		fixtures := importerFixtures[imp.name]
+		if len(fixtures) == 0 {
+			t.Errorf("%s: no fixture", imp.name)
+		}
+		for _, fixture := range fixtures {
+			checkValid(t, fixture, importFixture(t, imp.name, fixture))
+		}
+	}
+}
+
+// checkValid runs the Prism through the validator prism validate uses and
+// fails on any error
+func checkValid(t *testing.T, name string, prism *PrismDataStructs.Prism) {
+	t.Helper()
+	report := PrismDataStructs.Validate(prism)
+	for _, problem := range report.Problems {
+		if problem.Severity == PrismDataStructs.SeverityError {
+			t.Errorf("%s: %s: %s: %s", name, problem.IssueName, problem.Path, problem.Message)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// testsslFinding is one check from testssl.sh's JSON output
+type testsslFinding struct {
+	ID       string `json:"id"`
+	IP       string `json:"ip"`
+	Port     string `json:"port"`
+	Severity string `json:"severity"`
+	Finding  string `json:"finding"`
+}
+
+// testsslProtocols names the deprecated protocol checks
+var testsslProtocols = map[string]string{
+	"SSLv2":  "SSLv2",
+	"SSLv3":  "SSLv3",
+	"TLS1":   "TLS 1.0",
+	"TLS1_1": "TLS 1.1",
+}
+
+// testsslCipherLists names the cipher categories as testssl.sh prints them
+var testsslCipherLists = map[string]string{
+	"NULL":        "NULL ciphers (no encryption)",
+	"aNULL":       "Anonymous NULL ciphers (no authentication)",
+	"EXPORT":      "Export ciphers",
+	"LOW":         "LOW: 64 bit + DES, RC[2,4]",
+	"3DES_IDEA":   "Triple DES ciphers / IDEA",
+	"OBSOLETED":   "Obsoleted CBC ciphers",
+	"AVERAGE":     "Average ciphers",
+	"STRONG_NOFS": "Strong ciphers without forward secrecy",
+}
+
+// testsslCertificateChecks names the certificate checks
+var testsslCertificateChecks = map[string]string{
+	"cert_expirationStatus":   "Expiry",
+	"cert_chain_of_trust":     "Chain of trust",
+	"cert_trust":              "Host name",
+	"cert_signatureAlgorithm": "Signature algorithm",
+	"cert_keySize":            "Key size",
+}
+
+// importTestssl converts testssl.sh JSON output, either the flat --json
+// list or --jsonfile-pretty, to the consolidated TLS issues
+func importTestssl(r io.Reader) (*PrismDataStructs.Prism, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	var results []testsslFinding
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		if err = json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(data, []byte("{")):
+		if results, err = testsslPretty(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("not testssl.sh JSON output")
+	}
+
+	findings := newTLSFindings()
+	for _, result := range results {
+		rating, err := PrismDataStructs.ParseRiskRating(result.Severity)
+		if err != nil || !rating.AtLeast(PrismDataStructs.RiskLow) {
+			continue
+		}
+		host := result.affectedHost()
+
+		// Checks of a server with several certificates end <hostCert#1>
+		id, _, _ := strings.Cut(result.ID, " ")
+
+		switch {
+		case testsslProtocols[id] != "":
+			findings.add(tlsProtocols, host, rating, testsslProtocols[id], result.Finding)
+
+		case strings.HasPrefix(id, "cipherlist_") || strings.HasPrefix(id, "std_"):
+			category := id[strings.Index(id, "_")+1:]
+			if label, ok := testsslCipherLists[category]; ok {
+				category = label
+			}
+			findings.add(tlsCiphers, host, rating, "", category, "", result.Finding)
+
+		case strings.HasPrefix(id, "cipher-") || strings.HasPrefix(id, "cipher_x"):
+			// TLSv1.2 xc013 ECDHE-RSA-AES128-SHA ECDH 256 AES 128 TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+			// where the key exchange bits may be missing, so the cipher bits
+			// are the last number
+			fields := strings.Fields(result.Finding)
+			if len(fields) < 3 {
+				findings.add(tlsCiphers, host, rating, "", result.Finding, "", string(rating))
+				continue
+			}
+			var bits string
+			for _, field := range fields[3:] {
+				if _, err := strconv.Atoi(field); err == nil {
+					bits = field
+				}
+			}
+			findings.add(tlsCiphers, host, rating, fields[0], fields[2], bits, string(rating))
+
+		case id == "RC4":
+			findings.add(tlsCiphers, host, rating, "", "RC4", "", result.Finding)
+
+		case strings.HasPrefix(id, "cert_"):
+			check := id
+			if label, ok := testsslCertificateChecks[id]; ok {
+				check = label
+			}
+			findings.add(tlsCertificate, host, rating, check, result.Finding)
+
+		case id == "HSTS" || id == "HSTS_time":
+			check := "HSTS"
+			if id == "HSTS_time" {
+				check = "HSTS max-age"
+			}
+			findings.add(tlsHSTS, host, rating, check, result.Finding)
+		}
+	}
+	return findings.prism(), nil
+}
+
+// testsslPretty flattens --jsonfile-pretty output, where each scanned host
+// groups its checks in sections such as protocols and ciphers
+func testsslPretty(data []byte) ([]testsslFinding, error) {
+	var pretty struct {
+		ScanResult []json.RawMessage `json:"scanResult"`
+	}
+	if err := json.Unmarshal(data, &pretty); err != nil {
+		return nil, err
+	}
+
+	var results []testsslFinding
+	for _, scan := range pretty.ScanResult {
+		var target struct {
+			TargetHost string `json:"targetHost"`
+			IP         string `json:"ip"`
+			Port       string `json:"port"`
+		}
+		var sections map[string]json.RawMessage
+		if err := json.Unmarshal(scan, &target); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(scan, &sections); err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(sections))
+		for name := range sections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			var checks []testsslFinding
+			if json.Unmarshal(sections[name], &checks) != nil {
+				continue
+			}
+			for _, check := range checks {
+				check.IP = target.TargetHost + "/" + target.IP
+				check.Port = target.Port
+				results = append(results, check)
+			}
+		}
+	}
+	return results, nil
+}
+
+// affectedHost builds the host from testssl.sh's host/ip and port
+func (f testsslFinding) affectedHost() PrismDataStructs.AffectedHost {
+	var affectedHost PrismDataStructs.AffectedHost
+	hostname, ip, ok := strings.Cut(f.IP, "/")
+	if !ok {
+		hostname, ip = "", f.IP
+	}
+	if ip == "" {
+		ip = hostname
+	}
+	if hostname != ip {
+		affectedHost.Hostname = hostname
+	}
+	affectedHost.Ip = ip
+
+	if port, err := strconv.Atoi(f.Port); err == nil && port != 0 {
+		protocol := "tcp"
+		affectedHost.Port = PrismDataStructs.NewPort(port)
+		affectedHost.Protocol = &protocol
+	}
+	return affectedHost
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// tlsIssue is one of the consolidated TLS issues the testssl.sh and sslscan
+// importers produce, with the wording we report it with
+type tlsIssue struct {
+	name           string
+	summary        string
+	finding        string
+	recommendation string
+	references     []string
+
+	// columns heads the table of each host's findings
+	columns []string
+}
+
+// The consolidated TLS issues, in the order they are written
+const (
+	tlsProtocols = iota
+	tlsCiphers
+	tlsCertificate
+	tlsHSTS
+)
+
+var tlsIssues = []tlsIssue{
+	tlsProtocols: {
+		name:           "Deprecated SSL/TLS Protocols Supported",
+		summary:        "<p>The service supported deprecated versions of the SSL/TLS protocol.</p>",
+		finding:        "<p>The service supported one or more deprecated versions of the SSL/TLS protocol. SSLv2 and SSLv3 are affected by serious cryptographic weaknesses, including the POODLE attack, and TLS 1.0 and TLS 1.1 have been formally deprecated (RFC 8996) as they rely on outdated algorithms and are no longer accepted by modern clients or compliance standards such as PCI DSS.</p>",
+		recommendation: "<p>It is recommended that SSLv2, SSLv3, TLS 1.0 and TLS 1.1 are disabled, and that only TLS 1.2 and TLS 1.3 are offered.</p>",
+		references: []string{
+			"https://datatracker.ietf.org/doc/html/rfc8996",
+			"https://cheatsheetseries.owasp.org/cheatsheets/Transport_Layer_Security_Cheat_Sheet.html",
+		},
+		columns: []string{"Protocol", "Finding"},
+	},
+	tlsCiphers: {
+		name:           "Weak SSL/TLS Cipher Suites Supported",
+		summary:        "<p>The service supported weak SSL/TLS cipher suites.</p>",
+		finding:        "<p>The service supported cipher suites that offer no or weak encryption, such as NULL, anonymous, export grade, RC4, DES or 3DES ciphers, or ciphers with keys shorter than 128 bits. An attacker able to intercept traffic to the service may be able to decrypt or tamper with it.</p>",
+		recommendation: "<p>It is recommended that the weak cipher suites are disabled, and that only cipher suites using authenticated encryption (AES-GCM or ChaCha20-Poly1305) with forward secrecy (ECDHE) are offered.</p>",
+		references: []string{
+			"https://ssl-config.mozilla.org/",
+			"https://cheatsheetseries.owasp.org/cheatsheets/Transport_Layer_Security_Cheat_Sheet.html",
+		},
+		columns: []string{"Protocol", "Cipher", "Bits", "Finding"},
+	},
+	tlsCertificate: {
+		name:           "SSL/TLS Certificate Issues",
+		summary:        "<p>The certificate presented by the service could not be trusted.</p>",
+		finding:        "<p>The certificate presented by the service had one or more problems, such as having expired, not being issued by a trusted certificate authority, not matching the host name, or using a weak signature algorithm or key. Clients cannot reliably verify the identity of the service, which allows an attacker to impersonate it and users become accustomed to accepting certificate warnings.</p>",
+		recommendation: "<p>It is recommended that the certificate is replaced with one issued by a trusted certificate authority for the host names the service is accessed by, signed with SHA-256 or stronger and using an RSA key of at least 2048 bits or an ECDSA key of at least 256 bits.</p>",
+		references: []string{
+			"https://cheatsheetseries.owasp.org/cheatsheets/Transport_Layer_Security_Cheat_Sheet.html",
+		},
+		columns: []string{"Check", "Finding"},
+	},
+	tlsHSTS: {
+		name:           "HTTP Strict Transport Security Not Enforced",
+		summary:        "<p>The web server did not enforce HTTP Strict Transport Security.</p>",
+		finding:        "<p>The web server did not send an HTTP Strict Transport Security (HSTS) header, or sent one with a short lifetime. Without HSTS a browser may connect over plain HTTP before being redirected, allowing an attacker in a position to intercept traffic to strip the TLS connection.</p>",
+		recommendation: "<p>It is recommended that the Strict-Transport-Security header is sent on every HTTPS response with a max-age of at least one year (31536000 seconds) and, where all subdomains support HTTPS, the includeSubDomains directive.</p>",
+		references: []string{
+			"https://cheatsheetseries.owasp.org/cheatsheets/HTTP_Strict_Transport_Security_Cheat_Sheet.html",
+			"https://datatracker.ietf.org/doc/html/rfc6797",
+		},
+		columns: []string{"Check", "Finding"},
+	},
+}
+
+// tlsFindings collects the rows of each consolidated issue per host, keeping
+// hosts in the order they were scanned
+type tlsFindings struct {
+	ratings []PrismDataStructs.RiskRating
+	hosts   []*PrismDataStructs.HostSet
+	rows    []map[PrismDataStructs.HostKey][][]string
+}
+
+func newTLSFindings() *tlsFindings {
+	f := &tlsFindings{
+		ratings: make([]PrismDataStructs.RiskRating, len(tlsIssues)),
+		hosts:   make([]*PrismDataStructs.HostSet, len(tlsIssues)),
+		rows:    make([]map[PrismDataStructs.HostKey][][]string, len(tlsIssues)),
+	}
+	for i := range tlsIssues {
+		f.hosts[i] = PrismDataStructs.NewHostSet()
+		f.rows[i] = make(map[PrismDataStructs.HostKey][][]string)
+	}
+	return f
+}
+
+// add records a row of the issue for a host. The issue is rated as its most
+// severe row.
+func (f *tlsFindings) add(issue int, host PrismDataStructs.AffectedHost, rating PrismDataStructs.RiskRating, row ...string) {
+	if rating.Compare(f.ratings[issue]) > 0 {
+		f.ratings[issue] = rating
+	}
+	f.hosts[issue].Add(host)
+	key := host.Key()
+	f.rows[issue][key] = append(f.rows[issue][key], row)
+}
+
+// prism builds the issues that have any rows
+func (f *tlsFindings) prism() *PrismDataStructs.Prism {
+	prism := &PrismDataStructs.Prism{Version: PrismDataStructs.CurrentVersion}
+	today := time.Now().Format("2006-01-02")
+
+	for index, canned := range tlsIssues {
+		if f.hosts[index].Len() == 0 {
+			continue
+		}
+
+		issue := PrismDataStructs.Issue{
+			Name:               canned.name,
+			Finding:            canned.finding,
+			OriginalRiskRating: f.ratings[index],
+			Status:             "open",
+			ConfirmedAt:        today,
+			References:         append([]string(nil), canned.references...),
+		}
+		summary, recommendation := canned.summary, canned.recommendation
+		issue.Summary, issue.Recommendation = &summary, &recommendation
+
+		issue.AffectedHosts = f.hosts[index].Hosts()
+		for _, key := range f.hosts[index].Keys() {
+			table := htmlTable(append([][]string{canned.columns}, f.rows[index][key]...)...)
+			issue.TechnicalDetails += htmlSection("Host: "+key.String(), table)
+		}
+		prism.Issues = append(prism.Issues, issue)
+	}
+	return prism
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportTestssl(t *testing.T) {
+	// The flat and pretty outputs of the same scan import the same issues
+	for _, fixture := range []string{"testssl.json", "testssl-pretty.json"} {
+		prism := importFixture(t, "testssl", fixture)
+		checkIssues(t, prism, []wantIssue{
+			{
+				name:       "Deprecated SSL/TLS Protocols Supported",
+				rating:     PrismDataStructs.RiskHigh,
+				hosts:      []string{"shop.example.com 10.0.0.10:443/tcp", "10.0.0.11:8443/tcp"},
+				references: tlsIssues[tlsProtocols].references,
+			},
+			{
+				name:       "Weak SSL/TLS Cipher Suites Supported",
+				rating:     PrismDataStructs.RiskHigh,
+				hosts:      []string{"shop.example.com 10.0.0.10:443/tcp", "10.0.0.11:8443/tcp"},
+				references: tlsIssues[tlsCiphers].references,
+			},
+			{
+				name:       "SSL/TLS Certificate Issues",
+				rating:     PrismDataStructs.RiskCritical,
+				hosts:      []string{"shop.example.com 10.0.0.10:443/tcp"},
+				references: tlsIssues[tlsCertificate].references,
+			},
+			{
+				name:       "HTTP Strict Transport Security Not Enforced",
+				rating:     PrismDataStructs.RiskLow,
+				hosts:      []string{"shop.example.com 10.0.0.10:443/tcp", "10.0.0.11:8443/tcp"},
+				references: tlsIssues[tlsHSTS].references,
+			},
+		})
+
+		checkDetails(t, fixture, prism.Issues[0], "TLS 1.0", "TLS 1.1", "SSLv3", "offered (NOT ok)")
+		checkDetails(t, fixture, prism.Issues[1], "Triple DES ciphers / IDEA", "ECDHE-RSA-AES128-SHA", ">128<", "RC4")
+		checkDetails(t, fixture, prism.Issues[2], "Chain of trust", "failed (self signed).")
+		checkDetails(t, fixture, prism.Issues[3], "HSTS max-age", "86400 seconds")
+		if strings.Contains(prism.Issues[0].TechnicalDetails, "TLS1_2") || strings.Contains(prism.Issues[0].TechnicalDetails, "not offered") {
+			t.Errorf("%s: protocols rated OK were reported", fixture)
+		}
+	}
+}
+
+func TestImportSslscan(t *testing.T) {
+	prism := importFixture(t, "sslscan", "sslscan.xml")
+	checkIssues(t, prism, []wantIssue{
+		{
+			name:       "Deprecated SSL/TLS Protocols Supported",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"shop.example.com:443/tcp", "10.0.0.11:8443/tcp"},
+			references: tlsIssues[tlsProtocols].references,
+		},
+		{
+			name:       "Weak SSL/TLS Cipher Suites Supported",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"shop.example.com:443/tcp", "10.0.0.11:8443/tcp"},
+			references: tlsIssues[tlsCiphers].references,
+		},
+		{
+			name:       "SSL/TLS Certificate Issues",
+			rating:     PrismDataStructs.RiskHigh,
+			hosts:      []string{"shop.example.com:443/tcp"},
+			references: tlsIssues[tlsCertificate].references,
+		},
+	})
+
+	checkDetails(t, "sslscan.xml", prism.Issues[0], "TLSv1.0", "TLSv1.1", "SSLv3")
+	checkDetails(t, "sslscan.xml", prism.Issues[1], "DES-CBC3-SHA", "DES-CBC-SHA", "NULL-SHA")
+	checkDetails(t, "sslscan.xml", prism.Issues[2], "expired Jan  1 00:00:00 2023 GMT", "self-signed certificate for shop.example.com", "sha1WithRSAEncryption", "RSA 1024 bits")
+	for _, strong := range []string{"TLSv1.2", "TLSv1.3"} {
+		if strings.Contains(prism.Issues[0].TechnicalDetails, strong) {
+			t.Errorf("protocol %s was reported as deprecated", strong)
+		}
+	}
+	for _, strong := range []string{"TLS_AES_256_GCM_SHA384", "ECDHE-RSA-AES128-GCM-SHA256"} {
+		if strings.Contains(prism.Issues[1].TechnicalDetails, strong) {
+			t.Errorf("strong cipher %s was reported as weak", strong)
+		}
+	}
+}
+
+// checkDetails checks the issue's technical details include each string
+func checkDetails(t *testing.T, fixture string, issue PrismDataStructs.Issue, want ...string) {
+	t.Helper()
+	for _, want := range want {
+		if !strings.Contains(issue.TechnicalDetails, want) {
+			t.Errorf("%s: %s: technical details missing %q", fixture, issue.Name, want)
+		}
+	}
+}
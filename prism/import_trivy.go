@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// trivyReport is the part of Trivy's JSON report (--format json) the
+// importer reads
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Metadata     struct {
+		OS struct {
+			Family string `json:"Family"`
+			Name   string `json:"Name"`
+		} `json:"OS"`
+	} `json:"Metadata"`
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string   `json:"VulnerabilityID"`
+			PkgName          string   `json:"PkgName"`
+			InstalledVersion string   `json:"InstalledVersion"`
+			FixedVersion     string   `json:"FixedVersion"`
+			SeveritySource   string   `json:"SeveritySource"`
+			PrimaryURL       string   `json:"PrimaryURL"`
+			Title            string   `json:"Title"`
+			Description      string   `json:"Description"`
+			Severity         string   `json:"Severity"`
+			References       []string `json:"References"`
+
+			// CVSS is keyed by the source that scored it, such as nvd or ghsa
+			CVSS map[string]struct {
+				V2Vector string  `json:"V2Vector"`
+				V3Vector string  `json:"V3Vector"`
+				V2Score  float64 `json:"V2Score"`
+				V3Score  float64 `json:"V3Score"`
+			} `json:"CVSS"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// importTrivy converts a Trivy image, filesystem or repository scan to one
+// issue per vulnerable package, with the scanned artifact as the affected
+// host. Trivy does not report whether exploits exist.
+func importTrivy(r io.Reader) (*PrismDataStructs.Prism, error) {
+	var report trivyReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	host := imageAffectedHost(report.ArtifactName, report.Metadata.OS.Family+" "+report.Metadata.OS.Name)
+	findings := newPackageFindings()
+
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			// Trivy's UNKNOWN severity is not a rating
+			rating, err := PrismDataStructs.ParseRiskRating(v.Severity)
+			if err != nil {
+				rating = PrismDataStructs.RiskInfo
+			}
+
+			vuln := packageVuln{
+				id:          v.VulnerabilityID,
+				title:       v.Title,
+				description: v.Description,
+				rating:      rating,
+				installed:   v.InstalledVersion,
+				fixed:       v.FixedVersion,
+				url:         v.PrimaryURL,
+			}
+			if vuln.url == "" && len(v.References) > 0 {
+				vuln.url = v.References[0]
+			}
+
+			// The source Trivy took the severity from scores first, then NVD,
+			// then the rest by name
+			var others []string
+			for source := range v.CVSS {
+				others = append(others, source)
+			}
+			sort.Strings(others)
+			for _, source := range append([]string{v.SeveritySource, "nvd"}, others...) {
+				cvss, ok := v.CVSS[strings.ToLower(source)]
+				if !ok {
+					continue
+				}
+				if cvss.V3Vector != "" {
+					vuln.vector, vuln.score = cvss.V3Vector, cvss.V3Score
+					break
+				}
+				if cvss.V2Vector != "" && vuln.vector == "" {
+					vuln.vector, vuln.score = cvss.V2Vector, cvss.V2Score
+				}
+			}
+
+			findings.add(host, v.PkgName, vuln)
+		}
+	}
+	return findings.prism(), nil
+}
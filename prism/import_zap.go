@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// zapReport is ZAP's traditional JSON or XML report. The two carry the same
+// fields, with @ marking the JSON attributes.
+type zapReport struct {
+	Sites []zapSite `json:"site" xml:"site"`
+}
+
+type zapSite struct {
+	Name   string     `json:"@name" xml:"name,attr"`
+	Alerts []zapAlert `json:"alerts" xml:"alerts>alertitem"`
+}
+
+type zapAlert struct {
+	PluginId   string        `json:"pluginid" xml:"pluginid"`
+	Name       string        `json:"alert" xml:"alert"`
+	RiskCode   string        `json:"riskcode" xml:"riskcode"`
+	Confidence string        `json:"confidence" xml:"confidence"`
+	Desc       string        `json:"desc" xml:"desc"`
+	Solution   string        `json:"solution" xml:"solution"`
+	OtherInfo  string        `json:"otherinfo" xml:"otherinfo"`
+	Reference  string        `json:"reference" xml:"reference"`
+	CweId      string        `json:"cweid" xml:"cweid"`
+	WascId     string        `json:"wascid" xml:"wascid"`
+	Instances  []zapInstance `json:"instances" xml:"instances>instance"`
+	Tags       []struct {
+		Tag string `json:"tag" xml:"tag"`
+	} `json:"tags" xml:"tags>tag"`
+}
+
+type zapInstance struct {
+	URI       string `json:"uri" xml:"uri"`
+	Method    string `json:"method" xml:"method"`
+	Param     string `json:"param" xml:"param"`
+	Attack    string `json:"attack" xml:"attack"`
+	Evidence  string `json:"evidence" xml:"evidence"`
+	OtherInfo string `json:"otherinfo" xml:"otherinfo"`
+}
+
+// ZAP risk codes 0 to 3
+var zapRatings = []PrismDataStructs.RiskRating{
+	PrismDataStructs.RiskInfo,
+	PrismDataStructs.RiskLow,
+	PrismDataStructs.RiskMedium,
+	PrismDataStructs.RiskHigh,
+}
+
+var (
+	urlRegex = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+	// OWASP Top 10 tags such as OWASP_2021_A05, which become A05:2021
+	owaspTagRegex = regexp.MustCompile(`^OWASP_(\d{4})_(A\d{2})$`)
+)
+
+// wascReference is the WASC Threat Classification, which lists every WASC ID
+const wascReference = "http://projects.webappsec.org/w/page/13246978/Threat%20Classification"
+
+// zapFalsePositive is the confidence ZAP gives alerts a user has marked
+const zapFalsePositive = "0"
+
+// importZap converts a ZAP traditional JSON or XML report to one issue per
+// plugin, with one affected host per host and port and a table of the
+// instances found on each site in the technical details
+func importZap(r io.Reader) (*PrismDataStructs.Prism, error) {
+	report, err := decodeZapReport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := newIssueBuilder()
+	today := time.Now().Format("2006-01-02")
+
+	for _, site := range report.Sites {
+		for _, alert := range site.Alerts {
+			if alert.Confidence == zapFalsePositive {
+				continue
+			}
+
+			if issues.issue(alert.PluginId) == nil {
+				issues.add(alert.PluginId, zapIssue(alert, today))
+			}
+
+			for _, instance := range alert.Instances {
+				issues.addHost(alert.PluginId, zapAffectedHost(instance.URI))
+			}
+			if len(alert.Instances) == 0 {
+				issues.addHost(alert.PluginId, zapAffectedHost(site.Name))
+			}
+			issues.addDetails(alert.PluginId, zapTechnicalDetails(site, alert))
+		}
+	}
+	return issues.prism(), nil
+}
+
+// decodeZapReport reads either report format, telling them apart by the
+// first character
+func decodeZapReport(r io.Reader) (*zapReport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimLeft(data, " \t\r\n\ufeff")
+
+	var report zapReport
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		err = json.Unmarshal(data, &report)
+	case bytes.HasPrefix(data, []byte("<")):
+		err = xml.Unmarshal(data, &report)
+	default:
+		err = fmt.Errorf("not a ZAP JSON or XML report")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// zapIssue creates the issue for the first alert of a plugin
+func zapIssue(alert zapAlert, today string) PrismDataStructs.Issue {
+	var issue PrismDataStructs.Issue
+	issue.Name = alert.Name
+	issue.Finding = htmlBlock(alert.Desc)
+	issue.ConfirmedAt = today
+	issue.Status = "open"
+
+	issue.OriginalRiskRating = PrismDataStructs.RiskInfo
+	if code, err := strconv.Atoi(alert.RiskCode); err == nil && code >= 0 && code < len(zapRatings) {
+		issue.OriginalRiskRating = zapRatings[code]
+	}
+
+	if solution := htmlBlock(alert.Solution); solution != "" {
+		issue.Recommendation = &solution
+	}
+
+	issue.References = urlRegex.FindAllString(alert.Reference, -1)
+	// ZAP gives 0 or -1 for alerts without a classification
+	if id, err := strconv.Atoi(alert.CweId); err == nil && id > 0 {
+		issue.References = append(issue.References, "https://cwe.mitre.org/data/definitions/"+alert.CweId+".html")
+	}
+	// The classification has no page per ID, so the ID goes in the fragment
+	if id, err := strconv.Atoi(alert.WascId); err == nil && id > 0 {
+		issue.References = append(issue.References, wascReference+"#WASC-"+alert.WascId)
+	}
+
+	for _, tag := range alert.Tags {
+		if m := owaspTagRegex.FindStringSubmatch(tag.Tag); m != nil {
+			owaspId := m[2] + ":" + m[1]
+			issue.OwaspId = &owaspId
+			break
+		}
+	}
+	return issue
+}
+
+// zapAffectedHost builds the affected host for an instance URL
+func zapAffectedHost(uri string) PrismDataStructs.AffectedHost {
+	affectedHost := urlAffectedHost(uri, "")
+	if u, err := url.Parse(uri); err == nil && u.Scheme != "" {
+		service := u.Scheme
+		affectedHost.Service = &service
+	}
+	return affectedHost
+}
+
+// zapTechnicalDetails tables the instances of an alert on one site
+func zapTechnicalDetails(site zapSite, alert zapAlert) string {
+	rows := [][]string{{"URL", "Method", "Parameter", "Attack", "Evidence"}}
+	var otherInfo []string
+	for _, instance := range alert.Instances {
+		rows = append(rows, []string{instance.URI, instance.Method, instance.Param, instance.Attack, instance.Evidence})
+		if instance.OtherInfo != "" && !containsString(otherInfo, instance.OtherInfo) {
+			otherInfo = append(otherInfo, instance.OtherInfo)
+		}
+	}
+	if alert.OtherInfo != "" && !containsString(otherInfo, alert.OtherInfo) {
+		otherInfo = append(otherInfo, alert.OtherInfo)
+	}
+
+	td := htmlSection("Site: "+site.Name, "")
+	if len(rows) > 1 {
+		td += htmlTable(rows...)
+	}
+	for _, info := range otherInfo {
+		td += htmlBlock(info)
+	}
+	return td
+}
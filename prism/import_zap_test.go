@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestImportZap(t *testing.T) {
+	// The JSON and XML reports of the same scan import the same issues
+	for _, fixture := range []string{"zap.json", "zap.xml"} {
+		prism := importFixture(t, "zap", fixture)
+		checkIssues(t, prism, []wantIssue{
+			{
+				name:   "Content Security Policy (CSP) Header Not Set",
+				rating: PrismDataStructs.RiskMedium,
+				hosts:  []string{"shop.example.com:443/tcp", "admin.example.com:8080/tcp"},
+				references: []string{
+					"https://developer.mozilla.org/en-US/docs/Web/HTTP/CSP",
+					"https://cheatsheetseries.owasp.org/cheatsheets/Content_Security_Policy_Cheat_Sheet.html",
+					"https://cwe.mitre.org/data/definitions/693.html",
+					wascReference + "#WASC-15",
+				},
+			},
+			{
+				name:       "Cross Site Scripting (Reflected)",
+				rating:     PrismDataStructs.RiskHigh,
+				hosts:      []string{"admin.example.com:8080/tcp"},
+				references: []string{"https://owasp.org/www-community/attacks/xss/", "https://cwe.mitre.org/data/definitions/79.html", wascReference + "#WASC-8"},
+			},
+		})
+
+		csp, xss := prism.Issues[0], prism.Issues[1]
+		if csp.OwaspId == nil || *csp.OwaspId != "A05:2021" || xss.OwaspId == nil || *xss.OwaspId != "A03:2021" {
+			t.Errorf("%s: owasp ids %v, %v", fixture, csp.OwaspId, xss.OwaspId)
+		}
+		if !strings.Contains(csp.TechnicalDetails, "Site: https://shop.example.com") || !strings.Contains(csp.TechnicalDetails, "https://shop.example.com/search?q=test") {
+			t.Errorf("%s: technical details = %q", fixture, csp.TechnicalDetails)
+		}
+		if !strings.Contains(xss.TechnicalDetails, "&#34;&gt;&lt;scrIpt&gt;alert(1);&lt;/scRipt&gt;") {
+			t.Errorf("%s: attack not escaped in %q", fixture, xss.TechnicalDetails)
+		}
+	}
+}
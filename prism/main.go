@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// Exit codes shared by every subcommand
+const (
+	exitOK       = 0
+	exitWarnings = 1
+	exitFailure  = 2
+)
+
+// command is a subcommand of prism. A command with subcommands, such as
+// import, dispatches on its first argument instead of running itself.
+type command struct {
+	name        string
+	summary     string
+	run         func(args []string) int
+	subcommands []command
+}
+
+var commands = []command{
+	{name: "validate", summary: "Check a Prism file for structural problems", run: runValidate},
+	{name: "schema", summary: "Print the JSON Schema for Prism files", run: runSchema},
+	{name: "fix", summary: "Clean up the wording, references and CVSS of imported issues", run: runFix},
+	{name: "import", summary: "Convert scanner output to a Prism file", subcommands: importCommands()},
+	{name: "hosts", summary: "Work with the affected hosts of a Prism file", subcommands: []command{
+		{name: "remove", summary: "Remove hosts from every issue, dropping issues left without hosts", run: runHostsRemove},
+		{name: "enrich", summary: "Fill host OS, service, hostname and CPEs from an Nmap scan", run: runHostsEnrich},
+	}},
+	{name: "pipeline", summary: "Run the steps of a YAML pipeline file over one stream of issues", run: runPipeline},
+	{name: "headers", summary: "Check URLs for missing or misconfigured security headers", run: runHeaders},
+	{name: "merge", summary: "Merge several Prism files into one, deduplicating issues and hosts", run: runMerge},
+	{name: "diff", summary: "Compare an original Prism file with a retest", run: runDiff},
+	{name: "query", summary: "Filter issues and hosts with an expression", run: runQuery},
+	{name: "stats", summary: "Count issues by rating, host, port, CVE and CVSS severity", run: runStats},
+}
+
+func usage(path string, cmds []command) {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n", path)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range cmds {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	if path == "prism" {
+		fmt.Fprintln(os.Stderr, "Global flags, given before the command:")
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr)
+	}
+	fmt.Fprintf(os.Stderr, "Run '%s <command> -h' for the flags of a command.\n", path)
+}
+
+// dispatch runs the command named by args[0] from cmds
+func dispatch(path string, cmds []command, args []string) int {
+	if len(args) == 0 {
+		usage(path, cmds)
+		return exitFailure
+	}
+
+	name := args[0]
+	for _, cmd := range cmds {
+		if cmd.name == name {
+			if cmd.subcommands != nil {
+				return dispatch(path+" "+name, cmd.subcommands, args[1:])
+			}
+			return cmd.run(args[1:])
+		}
+	}
+
+	if name == "-h" || name == "-help" || name == "help" {
+		usage(path, cmds)
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n", path, name)
+	usage(path, cmds)
+	return exitFailure
+}
+
+func main() {
+	flag.BoolVar(&quiet, "q", false, "Only print warnings and errors")
+	noColor := flag.Bool("no-color", false, "Disable coloured output")
+	flag.Usage = func() { usage("prism", commands) }
+	flag.Parse()
+
+	if *noColor {
+		color.NoColor = true
+	}
+
+	os.Exit(dispatch("prism", commands, flag.Args()))
+}
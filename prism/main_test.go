@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain hides progress messages, which every command prints to stderr
+func TestMain(m *testing.M) {
+	quiet = true
+	os.Exit(m.Run())
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func runMerge(args []string) int {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	outputFile := flags.String("o", "", `Merged Prism file to write ("-" or empty for stdout)`)
+	identity := flags.String("identity", identityList(PrismDataStructs.DefaultIssueIdentities), "Comma separated issue identities to match on: name, nessus-id, nuclei-template, cves")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: prism merge [-o merged.json] [-identity list] file.json...\n\nOne of the files may be \"-\" for stdin.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	log := newLogger("merge")
+
+	if flags.NArg() == 0 {
+		return log.Failf("no input files given")
+	}
+
+	identities, err := PrismDataStructs.ParseIssueIdentities(*identity)
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	merger := PrismDataStructs.NewMerger(identities...)
+	for _, inputFile := range flags.Args() {
+		if err := mergeFile(merger, inputFile); err != nil {
+			return log.Failf("%s: %v", inputFile, err)
+		}
+	}
+
+	out, err := createFile(*outputFile)
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	merged := merger.Prism()
+	for i := range merged.Issues {
+		truncateCves(log, &merged.Issues[i])
+	}
+	if err := merged.Save(out, PrismDataStructs.DefaultSaveOptions); err != nil {
+		return log.Fail(err)
+	}
+
+	conflicts := merger.Conflicts()
+	for _, conflict := range conflicts {
+		log.Warnf("conflict: %s", conflict)
+	}
+	log.Infof("%d files merged into %d issues, %d conflicts", flags.NArg(), len(merged.Issues), len(conflicts))
+	return log.ExitCode()
+}
+
+// mergeFile streams one file into the merger
+func mergeFile(merger *PrismDataStructs.Merger, path string) error {
+	f, err := openFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := PrismDataStructs.NewReader(f)
+	if err != nil {
+		return err
+	}
+
+	for {
+		issue, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		merger.AddIssue(path, issue)
+	}
+
+	merger.AddHeader(path, reader.Header())
+	return nil
+}
+
+func identityList(identities []PrismDataStructs.IssueIdentity) string {
+	names := make([]string, len(identities))
+	for i, identity := range identities {
+		names[i] = string(identity)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+	"gopkg.in/yaml.v3"
+)
+
+// pipelineFile is the YAML file prism pipeline runs, for example
+//
+//	input: nuclei.json
+//	format: nuclei
+//	output: prism.json
+//	steps:
+//	  - hosts remove:
+//	      file: hosts.txt
+//	  - fix:
+//	      cvss: true
+//	  - query: rating >= Low
+type pipelineFile struct {
+	Input  string      `yaml:"input"`
+	Format string      `yaml:"format"`
+	Output string      `yaml:"output"`
+	Steps  []yaml.Node `yaml:"steps"`
+}
+
+// pipelineSteps builds each kind of step from its options, the value under
+// the step name in the YAML file
+var pipelineSteps = map[string]func(log *logger, options *yaml.Node) (transform, error){
+	"fix":          newFixStep,
+	"hosts remove": newHostsRemoveStep,
+	"hosts enrich": newHostsEnrichStep,
+	"query":        newQueryStep,
+}
+
+func runPipeline(args []string) int {
+	flags := flag.NewFlagSet("pipeline", flag.ExitOnError)
+	files := addIOFlags(flags, "Input file, overriding the pipeline's input", "Output file, overriding the pipeline's output")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: prism pipeline [-i in] [-o out] pipeline.yaml")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Steps:", strings.Join(pipelineStepNames(), ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	log := newLogger("pipeline")
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitFailure
+	}
+
+	pipeline, err := loadPipeline(flags.Arg(0))
+	if err != nil {
+		return log.Fail(err)
+	}
+	if *files.input == "" {
+		*files.input = pipeline.Input
+	}
+	if *files.output == "" {
+		*files.output = pipeline.Output
+	}
+
+	transforms, err := pipeline.transforms(log)
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	in, err := files.openInput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer in.Close()
+
+	source, clean, err := pipeline.source(log, in)
+	if err != nil {
+		return log.Fail(err)
+	}
+	if clean {
+		transforms = importFix(log, transforms)
+	}
+
+	out, err := files.createOutput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	writer, err := PrismDataStructs.NewWriter(out, PrismDataStructs.DefaultSaveOptions)
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	if err = runTransforms(source, writer, transforms...); err != nil {
+		return log.Fail(err)
+	}
+	return log.ExitCode()
+}
+
+func loadPipeline(path string) (*pipelineFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pipeline pipelineFile
+	if err = decodeStrict(data, &pipeline); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &pipeline, nil
+}
+
+// source reads the input as a Prism file, or through the importer named by
+// format, and reports whether the importer wants the fix clean-ups
+func (p *pipelineFile) source(log *logger, r io.Reader) (issueSource, bool, error) {
+	if p.Format == "" || p.Format == "prism" {
+		reader, err := PrismDataStructs.NewReader(r)
+		return reader, false, err
+	}
+
+	imp, err := findImporter(p.Format)
+	if err != nil {
+		return nil, false, err
+	}
+	prism, err := imp.importFrom(log, r)
+	if err != nil {
+		return nil, false, err
+	}
+	return &prismSource{prism: prism}, imp.clean, nil
+}
+
+// importFix applies the clean-ups of an importer that wants them before the
+// first step. When the steps already fix the issues, that step does it
+// instead, so the clean-ups run once.
+func importFix(log *logger, transforms []transform) []transform {
+	for _, t := range transforms {
+		if fix, ok := t.(*fixTransform); ok {
+			fix.imported = true
+			return transforms
+		}
+	}
+	return append([]transform{&fixTransform{log: log, imported: true}}, transforms...)
+}
+
+// transforms builds the steps in order. A step is either a bare name, such
+// as "- fix", or a name mapped to its options.
+func (p *pipelineFile) transforms(log *logger) ([]transform, error) {
+	var transforms []transform
+	for i := range p.Steps {
+		node := &p.Steps[i]
+
+		var name string
+		var options *yaml.Node
+		switch {
+		case node.Kind == yaml.ScalarNode:
+			name = node.Value
+		case node.Kind == yaml.MappingNode && len(node.Content) == 2:
+			name, options = node.Content[0].Value, node.Content[1]
+		default:
+			return nil, fmt.Errorf("step %d (line %d): expected a step name or a single name: options mapping", i+1, node.Line)
+		}
+
+		newStep, ok := pipelineSteps[name]
+		if !ok {
+			return nil, fmt.Errorf("step %d (line %d): unknown step %q", i+1, node.Line, name)
+		}
+		t, err := newStep(log, options)
+		if err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i+1, name, err)
+		}
+		transforms = append(transforms, t)
+	}
+	return transforms, nil
+}
+
+func pipelineStepNames() []string {
+	var names []string
+	for name := range pipelineSteps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeStrict decodes YAML into v, rejecting keys v does not have so a
+// misspelt key is not silently ignored
+func decodeStrict(data []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// decodeOptions decodes a step's options, which may be missing, rejecting
+// any option not in known
+func decodeOptions(options *yaml.Node, v interface{}, known ...string) error {
+	if options == nil || options.Tag == "!!null" {
+		return nil
+	}
+	if options.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of options", options.Line)
+	}
+	for i := 0; i < len(options.Content); i += 2 {
+		key := options.Content[i]
+		if !containsString(known, key.Value) {
+			return fmt.Errorf("line %d: unknown option %q", key.Line, key.Value)
+		}
+	}
+	return options.Decode(v)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newFixStep(log *logger, options *yaml.Node) (transform, error) {
+	var opts struct {
+		CVSS bool `yaml:"cvss"`
+	}
+	if err := decodeOptions(options, &opts, "cvss"); err != nil {
+		return nil, err
+	}
+	return &fixTransform{log: log, fixCVSS: opts.CVSS}, nil
+}
+
+func newHostsRemoveStep(log *logger, options *yaml.Node) (transform, error) {
+	var opts struct {
+		Hosts []string `yaml:"hosts"`
+		File  string   `yaml:"file"`
+	}
+	if err := decodeOptions(options, &opts, "hosts", "file"); err != nil {
+		return nil, err
+	}
+
+	var hosts []PrismDataStructs.HostKey
+	for _, host := range opts.Hosts {
+		hostKey, err := PrismDataStructs.ParseHostKey(host)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, hostKey)
+	}
+	if opts.File != "" {
+		fromFile, err := readHostFile(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, fromFile...)
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts to remove (hosts or file)")
+	}
+	return &hostsRemoveTransform{log: log, hosts: hosts}, nil
+}
+
+func newHostsEnrichStep(log *logger, options *yaml.Node) (transform, error) {
+	var opts struct {
+		Nmap      string `yaml:"nmap"`
+		OpenPorts bool   `yaml:"open-ports"`
+	}
+	if err := decodeOptions(options, &opts, "nmap", "open-ports"); err != nil {
+		return nil, err
+	}
+	if opts.Nmap == "" {
+		return nil, fmt.Errorf("no Nmap file specified (nmap)")
+	}
+	return newHostsEnrichTransform(log, opts.Nmap, opts.OpenPorts)
+}
+
+// newQueryStep takes the expression as the options, or under expression
+func newQueryStep(log *logger, options *yaml.Node) (transform, error) {
+	var opts struct {
+		Expression string `yaml:"expression"`
+	}
+	if options != nil && options.Kind == yaml.ScalarNode {
+		opts.Expression = options.Value
+	} else if err := decodeOptions(options, &opts, "expression"); err != nil {
+		return nil, err
+	}
+
+	query, err := PrismDataStructs.ParseQuery(opts.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return queryTransform{query}, nil
+}
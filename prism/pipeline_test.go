@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestPipeline(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "prism.json")
+	pipeline := writeFile(t, dir, "pipeline.yaml", `input: testdata/openvas.xml
+format: openvas
+output: `+output+`
+steps:
+  - hosts remove:
+      hosts: [10.0.0.1]
+  - query: rating >= Medium
+`)
+
+	// The OpenVAS import's clean-ups run first, and only note what the
+	// scanner did not report
+	if code := runPipeline([]string{pipeline}); code != exitOK {
+		t.Fatalf("exit code %d, want %d", code, exitOK)
+	}
+
+	prism, err := PrismDataStructs.LoadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIssues(t, prism, []wantIssue{{
+		name:       "OpenSSL Denial of Service Vulnerability (20220315)",
+		rating:     PrismDataStructs.RiskHigh,
+		hosts:      []string{"[2001:db8::10]:8443/tcp"},
+		cves:       []string{"CVE-2022-0778"},
+		cvss:       "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+		references: []string{"https://www.openssl.org/news/secadv/20220315.txt"},
+	}})
+	checkValid(t, "pipeline output", prism)
+}
+
+func TestPipelineFixStep(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "prism.json")
+	path := writeFile(t, dir, "pipeline.yaml", `input: testdata/openvas.xml
+format: openvas
+output: `+output+`
+steps:
+  - query: rating >= Medium
+  - fix:
+      cvss: true
+`)
+
+	// The fix step does the OpenVAS import's clean-ups rather than running
+	// them a second time
+	pipeline, err := loadPipeline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transforms, err := pipeline.transforms(newLogger("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transforms = importFix(newLogger("test"), transforms)
+	fixes := 0
+	for _, step := range transforms {
+		if fix, ok := step.(*fixTransform); ok {
+			fixes++
+			if !fix.imported || !fix.fixCVSS {
+				t.Errorf("fix step imported %v, cvss %v, want both", fix.imported, fix.fixCVSS)
+			}
+		}
+	}
+	if len(transforms) != 2 || fixes != 1 {
+		t.Errorf("%d steps with %d fixes, want the 2 steps with 1 fix", len(transforms), fixes)
+	}
+
+	if code := runPipeline([]string{path}); code != exitOK {
+		t.Fatalf("exit code %d, want %d", code, exitOK)
+	}
+	prism, err := PrismDataStructs.LoadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValid(t, "pipeline output", prism)
+}
+
+func TestPipelineRejectsBadSteps(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name, yaml, want string
+	}{
+		{"misspelt key", "inptu: in.json\n", "field inptu not found"},
+		{"unknown step", "steps:\n  - sort\n", `unknown step "sort"`},
+		{"unknown option", "steps:\n  - fix:\n      cvs: true\n", `unknown option "cvs"`},
+		{"no hosts", "steps:\n  - hosts remove:\n", "no hosts to remove"},
+		{"bad query", "steps:\n  - query: rating >>\n", "step 1 (query)"},
+		{"list of steps", "steps:\n  - fix: {}\n    query: rating >= Low\n", "expected a step name"},
+	} {
+		pipeline, err := loadPipeline(writeFile(t, dir, "pipeline.yaml", test.yaml))
+		if err == nil {
+			_, err = pipeline.transforms(newLogger("test"))
+		}
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "prism.json")
+	for _, name := range []string{"openvas", "zap"} {
+		imp, err := findImporter(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixture := filepath.Join("testdata", importerFixtures[name][0])
+		if code := runImport(imp, []string{"-i", fixture, "-o", output}); code != exitOK {
+			t.Errorf("%s: exit code %d, want %d", name, code, exitOK)
+			continue
+		}
+
+		prism, err := PrismDataStructs.LoadFile(output)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(prism.Issues) == 0 {
+			t.Errorf("%s: no issues written", name)
+		}
+		checkValid(t, name, prism)
+	}
+}
+
+// writeFile writes a file in dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
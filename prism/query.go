@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// issueSink receives the issues a command selects, in one of the output formats
+type issueSink interface {
+	WriteIssue(issue *PrismDataStructs.Issue) error
+	Close(header *PrismDataStructs.Prism) error
+}
+
+func runQuery(args []string) int {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	files := addIOFlags(flags, "Prism file to query", "File to write to")
+	format := flags.String("format", "prism", "Output format: prism, table or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: prism query -i prism.json [-format prism|table|csv] [-o out] 'expression'")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Example: prism query -i prism.json -format table 'rating >= High and ip in 10.1.0.0/16 and has cve'")
+		fmt.Fprintln(os.Stderr, "Fields:", strings.Join(PrismDataStructs.QueryFields(), ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	log := newLogger("query")
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitFailure
+	}
+
+	query, err := PrismDataStructs.ParseQuery(strings.Join(flags.Args(), " "))
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	in, err := files.openInput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer in.Close()
+
+	reader, err := PrismDataStructs.NewReader(in)
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	out, err := files.createOutput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	var sink issueSink
+	switch *format {
+	case "prism":
+		sink, err = PrismDataStructs.NewWriter(out, PrismDataStructs.DefaultSaveOptions)
+	case "table":
+		sink = newTableSink(out)
+	case "csv":
+		sink, err = newCSVSink(out)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	if err = runTransforms(reader, sink, queryTransform{query}); err != nil {
+		return log.Fail(err)
+	}
+	return log.ExitCode()
+}
+
+// queryTransform keeps the issues a query matches, narrowed to the hosts
+// that matched
+type queryTransform struct {
+	query *PrismDataStructs.Query
+}
+
+func (t queryTransform) Apply(issue *PrismDataStructs.Issue) (*PrismDataStructs.Issue, error) {
+	if filtered, ok := t.query.Filter(issue); ok {
+		return filtered, nil
+	}
+	return nil, nil
+}
+
+// tableSink prints one line per issue with its hosts
+type tableSink struct {
+	tw *tabwriter.Writer
+}
+
+func newTableSink(w io.Writer) *tableSink {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RATING\tISSUE\tHOSTS\tCVES")
+	return &tableSink{tw: tw}
+}
+
+func (s *tableSink) WriteIssue(issue *PrismDataStructs.Issue) error {
+	var hosts []string
+	for _, host := range issue.AffectedHosts {
+		hosts = append(hosts, host.Key().String())
+	}
+	_, err := fmt.Fprintf(s.tw, "%s\t%s\t%s\t%s\n", issue.OriginalRiskRating, issue.Name, strings.Join(hosts, ", "), strings.Join(issueCves(issue), ", "))
+	return err
+}
+
+func (s *tableSink) Close(header *PrismDataStructs.Prism) error {
+	return s.tw.Flush()
+}
+
+// csvSink writes one row per affected host, or one row for an issue without hosts
+type csvSink struct {
+	w *csv.Writer
+}
+
+func newCSVSink(w io.Writer) (*csvSink, error) {
+	s := &csvSink{w: csv.NewWriter(w)}
+	return s, s.w.Write([]string{"rating", "issue", "ip", "hostname", "port", "protocol", "cvss", "cves"})
+}
+
+func (s *csvSink) WriteIssue(issue *PrismDataStructs.Issue) error {
+	cvss := ""
+	if c, err := issue.CVSS(); err == nil && c.Scored {
+		cvss = strconv.FormatFloat(c.BaseScore, 'f', 1, 64)
+	}
+	cves := strings.Join(issueCves(issue), " ")
+
+	if len(issue.AffectedHosts) == 0 {
+		return s.w.Write([]string{issue.OriginalRiskRating.String(), issue.Name, "", "", "", "", cvss, cves})
+	}
+	for _, host := range issue.AffectedHosts {
+		key := host.Key()
+		port := ""
+		if key.Port != 0 {
+			port = strconv.Itoa(key.Port)
+		}
+		if err := s.w.Write([]string{issue.OriginalRiskRating.String(), issue.Name, key.IP, key.Hostname, port, key.Protocol, cvss, cves}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *csvSink) Close(header *PrismDataStructs.Prism) error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func issueCves(issue *PrismDataStructs.Issue) []string {
+	if issue.Cves == nil {
+		return nil
+	}
+	return *issue.Cves
+}
@@ -24,7 +24,7 @@ fi
 $nuclei -update-templates
 
 mkdir -p ./output/nuclei
-mkdir -p ./output/custom
+mkdir -p ./output/prism
 
 # Read in the file containing the list of URLs as a flag
 while getopts f: flag
@@ -37,7 +37,7 @@ done
 # Check if the -h flag was used
 if [ -z "$file" ]
 then
-    echo "Usage: ./run.sh -f <file>"
+    echo "Usage: ./run-nuclei.sh -f <file>"
     exit
 fi
 
@@ -51,4 +51,8 @@ fi
 # Read in the file containing the list of URLs
 cat $file | $nuclei -t ~/nuclei-templates/ -o output/nuclei/nuclei_output_$(date +'%F').json -json
 
-$go run main.go 
\ No newline at end of file
+# Build the prism tool next to this script and convert the results
+prism_dir=$(dirname "$0")
+(cd "$prism_dir" && $go build -o prism .) || exit 1
+"$prism_dir/prism" import nuclei -i output/nuclei/nuclei_output_$(date +'%F').json -o output/prism/prism_nuclei_$(date +'%F').json
+ 
\ No newline at end of file
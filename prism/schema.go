@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func runSchema(args []string) int {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	outputFile := flags.String("o", "", `File to write the schema to ("-" or empty for stdout)`)
+	flags.Parse(args)
+
+	log := newLogger("schema")
+
+	out, err := createFile(*outputFile)
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	if _, err = out.Write(PrismDataStructs.SchemaJSON); err != nil {
+		return log.Fail(err)
+	}
+	return exitOK
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func runStats(args []string) int {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	files := addIOFlags(flags, "Prism file to summarise", "File to write to")
+	format := flags.String("format", "text", "Output format: text, json or csv")
+	top := flags.Int("top", 10, "Number of hosts and issues to rank, 0 for all")
+	flags.Parse(args)
+
+	log := newLogger("stats")
+
+	if *format != "text" && *format != "json" && *format != "csv" {
+		return log.Failf("unknown format %q", *format)
+	}
+
+	in, err := files.openInput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer in.Close()
+
+	report, err := statsReport(in, *top)
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	out, err := files.createOutput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case "csv":
+		err = writeStatsCSV(out, report)
+	default:
+		err = printStats(out, report)
+	}
+	if err != nil {
+		return log.Fail(err)
+	}
+	return exitOK
+}
+
+// statsReport streams the file through the counters
+func statsReport(r io.Reader, top int) (*PrismDataStructs.StatsReport, error) {
+	reader, err := PrismDataStructs.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := PrismDataStructs.NewStats()
+	for {
+		issue, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		stats.Add(issue)
+	}
+	return stats.Report(top), nil
+}
+
+// statsSection is one breakdown, named for the CSV output and titled for text
+type statsSection struct {
+	name   string
+	title  string
+	counts []PrismDataStructs.Count
+}
+
+// statsSections lists the breakdowns in the order they are printed
+func statsSections(report *PrismDataStructs.StatsReport) []statsSection {
+	return []statsSection{
+		{"rating", "By risk rating", report.ByRating},
+		{"cvss", "By CVSS severity", report.CVSS},
+		{"host", "Most affected hosts", report.ByHost},
+		{"issue", "Most widespread issues", report.TopIssues},
+		{"port", "By port", report.ByPort},
+		{"service", "By service", report.ByService},
+		{"cve", "By CVE", report.ByCve},
+	}
+}
+
+func printStats(w io.Writer, report *PrismDataStructs.StatsReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Issues\t%d\n", report.Issues)
+	fmt.Fprintf(tw, "Affected hosts\t%d\n", report.AffectedHosts)
+	fmt.Fprintf(tw, "Unique hosts\t%d\n", report.UniqueHosts)
+	fmt.Fprintf(tw, "Exploit available\t%d\n", report.ExploitAvailable)
+
+	for _, section := range statsSections(report) {
+		if len(section.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%s\n", section.title)
+		for _, count := range section.counts {
+			fmt.Fprintf(tw, "  %s\t%d\n", count.Key, count.Count)
+		}
+	}
+	return tw.Flush()
+}
+
+// writeStatsCSV writes every figure as a section,key,count row
+func writeStatsCSV(w io.Writer, report *PrismDataStructs.StatsReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"section", "key", "count"})
+	cw.Write([]string{"total", "issues", strconv.Itoa(report.Issues)})
+	cw.Write([]string{"total", "affected_hosts", strconv.Itoa(report.AffectedHosts)})
+	cw.Write([]string{"total", "unique_hosts", strconv.Itoa(report.UniqueHosts)})
+	cw.Write([]string{"total", "exploit_available", strconv.Itoa(report.ExploitAvailable)})
+
+	for _, section := range statsSections(report) {
+		for _, count := range section.counts {
+			cw.Write([]string{section.name, count.Key, strconv.Itoa(count.Count)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
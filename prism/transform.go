@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+// transform is one per-issue step, such as fix or hosts remove. Apply
+// returns the issue to pass on, which may be the same issue changed in
+// place, or nil to drop it.
+type transform interface {
+	Apply(issue *PrismDataStructs.Issue) (*PrismDataStructs.Issue, error)
+}
+
+// issueSource yields issues one at a time, then the rest of the file once
+// Next has returned io.EOF. PrismDataStructs.Reader is one.
+type issueSource interface {
+	Next() (*PrismDataStructs.Issue, error)
+	Header() *PrismDataStructs.Prism
+}
+
+// prismSource yields the issues of a Prism already in memory, such as the
+// output of an importer
+type prismSource struct {
+	prism *PrismDataStructs.Prism
+	next  int
+}
+
+func (s *prismSource) Next() (*PrismDataStructs.Issue, error) {
+	if s.next >= len(s.prism.Issues) {
+		return nil, io.EOF
+	}
+	s.next++
+	return &s.prism.Issues[s.next-1], nil
+}
+
+func (s *prismSource) Header() *PrismDataStructs.Prism {
+	if s.next < len(s.prism.Issues) {
+		return nil
+	}
+	return s.prism
+}
+
+// flusher is a transform that adds issues of its own, such as a summary of
+// every host it saw, once the last issue has passed through it
+type flusher interface {
+	Flush() ([]*PrismDataStructs.Issue, error)
+}
+
+// runTransforms passes every issue from source through the transforms in
+// order and writes the survivors to sink. Issues a transform flushes pass
+// through the transforms after it.
+func runTransforms(source issueSource, sink issueSink, transforms ...transform) error {
+	for {
+		issue, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err = applyTransforms(sink, issue, transforms); err != nil {
+			return err
+		}
+	}
+
+	for i, t := range transforms {
+		f, ok := t.(flusher)
+		if !ok {
+			continue
+		}
+		issues, err := f.Flush()
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			if err = applyTransforms(sink, issue, transforms[i+1:]); err != nil {
+				return err
+			}
+		}
+	}
+	return sink.Close(source.Header())
+}
+
+func applyTransforms(sink issueSink, issue *PrismDataStructs.Issue, transforms []transform) error {
+	var err error
+	for _, t := range transforms {
+		if issue, err = t.Apply(issue); err != nil {
+			return err
+		}
+		if issue == nil {
+			return nil
+		}
+	}
+	return sink.WriteIssue(issue)
+}
+
+// transformFile streams the -i file through the transforms to the -o file
+func transformFile(files ioFlags, transforms ...transform) error {
+	in, err := files.openInput()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	reader, err := PrismDataStructs.NewReader(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := files.createOutput()
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer, err := PrismDataStructs.NewWriter(out, PrismDataStructs.DefaultSaveOptions)
+	if err != nil {
+		return err
+	}
+	return runTransforms(reader, writer, transforms...)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	files := addIOFlags(flags, "Prism file to validate", "File to write the report to")
+	format := flags.String("format", "text", "Report format: text or json")
+	flags.Parse(args)
+
+	log := newLogger("validate")
+
+	if *format != "text" && *format != "json" {
+		return log.Failf("unknown format %q", *format)
+	}
+
+	if *files.input == "" {
+		return log.Failf("no input file specified (-i)")
+	}
+	report := validateFile(files)
+
+	out, err := files.createOutput()
+	if err != nil {
+		return log.Fail(err)
+	}
+	defer out.Close()
+
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = printValidationReport(out, report)
+	}
+	if err != nil {
+		return log.Fail(err)
+	}
+
+	switch {
+	case report.Errors > 0:
+		return exitFailure
+	case report.Warnings > 0:
+		return exitWarnings
+	}
+	return exitOK
+}
+
+// validateFile streams the file through the validator. An issue that cannot
+// be decoded is reported at the value that failed and the rest are still
+// checked; a file that cannot be read at all is a single error at the root.
+func validateFile(files ioFlags) *PrismDataStructs.ValidationReport {
+	validator := PrismDataStructs.NewValidator()
+	fail := func(err error) *PrismDataStructs.ValidationReport {
+		report := validator.Report()
+		report.Add(PrismDataStructs.Problem{
+			Severity: PrismDataStructs.SeverityError,
+			Path:     "$",
+			Message:  err.Error(),
+		})
+		return report
+	}
+
+	f, err := files.openInput()
+	if err != nil {
+		return fail(err)
+	}
+	defer f.Close()
+
+	reader, err := PrismDataStructs.NewReader(f)
+	if err != nil {
+		return fail(err)
+	}
+
+	for issueIndex := 0; ; issueIndex++ {
+		issue, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var decodeErr *PrismDataStructs.IssueDecodeError
+		if errors.As(err, &decodeErr) {
+			validator.DecodeError(decodeErr)
+			continue
+		}
+		if err != nil {
+			return fail(err)
+		}
+		validator.Issue(issueIndex, issue)
+	}
+
+	validator.Header(reader.Header())
+	return validator.Report()
+}
+
+func printValidationReport(w io.Writer, report *PrismDataStructs.ValidationReport) error {
+	for _, problem := range report.Problems {
+		location := problem.Path
+		if problem.IssueIndex != nil {
+			location = fmt.Sprintf("%s (issue %d %q)", problem.Path, *problem.IssueIndex, problem.IssueName)
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", problem.Severity, location, problem.Message)
+	}
+	_, err := fmt.Fprintf(w, "%d issues checked, %d errors, %d warnings\n", report.Issues, report.Errors, report.Warnings)
+	return err
+}
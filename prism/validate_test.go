@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MantisSTS/PrismTools/PrismDataStructs"
+)
+
+func TestValidateReportsIssuesThatFailToDecode(t *testing.T) {
+	dir := t.TempDir()
+	input := writeFile(t, dir, "prism.json", `{"version":1,"issues":[
+		{"name":"Open Ports","affected_hosts":[{"ip":"10.0.0.1","port":"https"}]},
+		{"name":"Weak Ciphers","status":"Open","confirmed_at":"2024-01-01","affected_hosts":[{"ip":"10.0.0.2","port":443}],"cvss_vector":"CVSS:3.1/AV:N/"}
+	]}`)
+	output := filepath.Join(dir, "report.json")
+
+	if code := runValidate([]string{"-i", input, "-o", output, "-format", "json"}); code != exitFailure {
+		t.Errorf("exit code %d, want %d", code, exitFailure)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report PrismDataStructs.ValidationReport
+	if err = json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Issues != 2 {
+		t.Errorf("%d issues checked, want 2", report.Issues)
+	}
+
+	issues := map[int]bool{}
+	for _, problem := range report.Problems {
+		if problem.IssueIndex == nil {
+			continue
+		}
+		issues[*problem.IssueIndex] = true
+		if *problem.IssueIndex == 0 && (problem.Path != "$.issues[0].affected_hosts[0].port" || problem.IssueName != "Open Ports") {
+			t.Errorf("decode error at %s (%q), want $.issues[0].affected_hosts[0].port (\"Open Ports\")", problem.Path, problem.IssueName)
+		}
+	}
+	if !issues[0] || !issues[1] {
+		t.Errorf("problems reported for issues %v, want both", issues)
+	}
+}